@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+
+	"psc-project/pkg/sat"
+)
+
+// readFormulas reads one formula per line. A '#' starts a comment running to
+// the end of the line, and the lines left blank are skipped
+func readFormulas(r io.Reader) ([]string, error) {
+	var formulas []string
+	err := scanFormulas(r, func(formula string) {
+		formulas = append(formulas, formula)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return formulas, nil
+}
+
+// scanFormulas is like readFormulas, but calls fn on each formula as soon as
+// it is read instead of collecting them
+func scanFormulas(r io.Reader, fn func(formula string)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := stripComment(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fn(line)
+	}
+	return scanner.Err()
+}
+
+// stripComment returns the line without its comment and surrounding spaces
+func stripComment(line string) string {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+// parseDefinition reads the line if it defines a macro, as in
+// "def parity = a ^ b ^ c", returning its name and its formula. The boolean
+// is false if the line is a formula rather than a definition
+func parseDefinition(line string) (string, string, bool, error) {
+	rest, ok := strings.CutPrefix(line, "def ")
+	if !ok {
+		return "", "", false, nil
+	}
+
+	name, body, ok := strings.Cut(rest, "=")
+	name, body = strings.TrimSpace(name), strings.TrimSpace(body)
+	switch {
+	case !ok || body == "":
+		return "", "", true, fmt.Errorf("invalid definition, expected def name = formula")
+	case !token.IsIdentifier(name) || name == "true" || name == "false":
+		return "", "", true, fmt.Errorf("invalid macro name '%s'", name)
+	case sat.IsBuiltin(name):
+		return "", "", true, fmt.Errorf("invalid macro name '%s', it names a builtin function", name)
+	}
+	return name, body, true, nil
+}
+
+// repl prompts on w for a formula at a time read from r, calling fn on each
+// one, until the input ends or the line is "quit"
+func repl(r io.Reader, w io.Writer, fn func(formula string)) error {
+	scanner := bufio.NewScanner(r)
+	for {
+		fmt.Fprint(w, "> ")
+		if !scanner.Scan() {
+			// End the prompt line when the input ends
+			fmt.Fprintln(w)
+			return scanner.Err()
+		}
+
+		line := stripComment(scanner.Text())
+		switch line {
+		case "quit":
+			return nil
+		case "":
+			continue
+		}
+		fn(line)
+	}
+}
+
+// stdinIsPiped reports whether the standard input comes from a pipe or a
+// file rather than a terminal
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// readFormulasFile reads the formulas from the file at path
+func readFormulasFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readFormulas(f)
+}
+
+// readDIMACSFile reads the DIMACS instance from the file at path
+func readDIMACSFile(path string) (string, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	return sat.ParseDIMACS(f)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile writes the content to a file of a temporary directory, returning
+// its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadFormulasFile(t *testing.T) {
+	path := writeFile(t, "formulas.txt", "a && b\n  a || !a  \n\nc -> d\n")
+	formulas, err := readFormulasFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a && b", "a || !a", "c -> d"}; !reflect.DeepEqual(formulas, want) {
+		t.Errorf("got %q, want %q", formulas, want)
+	}
+
+	if _, err := readFormulasFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestReadFormulasComments(t *testing.T) {
+	content := "# Constraints of the example\n" +
+		"a && b  # main constraint\n" +
+		"\n" +
+		"   # indented comment\n" +
+		"\t\n" +
+		"!c#no space before it\n" +
+		"a || b ||c\n" +
+		"#\n"
+	formulas, err := readFormulas(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a && b", "!c", "a || b ||c"}; !reflect.DeepEqual(formulas, want) {
+		t.Errorf("got %q, want %q", formulas, want)
+	}
+
+	// Only the real formulas are solved
+	path := writeFile(t, "formulas.txt", content)
+	stdout, stderr, code := runMain(t, "", "-json", "-file", path)
+	if code != exitSatisfiable {
+		t.Errorf("got exit code %d, want %d; stderr: %s", code, exitSatisfiable, stderr)
+	}
+	results := decodeResults(t, []byte(stdout))
+	var solved []string
+	for _, result := range results {
+		formula, _ := result["formula"].(string)
+		solved = append(solved, formula)
+	}
+	if !reflect.DeepEqual(solved, formulas) {
+		t.Errorf("solved %q, want %q", solved, formulas)
+	}
+}
+
+func TestRepl(t *testing.T) {
+	var lines []string
+	var w bytes.Buffer
+	input := "a && b\n\n  a || !a  \n# comment\nquit\nc\n"
+	if err := repl(strings.NewReader(input), &w, func(formula string) {
+		lines = append(lines, formula)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a && b", "a || !a"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+	if prompts := strings.Count(w.String(), "> "); prompts != 5 {
+		t.Errorf("got %d prompts, want 5: %q", prompts, w.String())
+	}
+
+	// Without quit, until the input ends
+	lines = nil
+	if err := repl(strings.NewReader("a\nb"), &w, func(formula string) {
+		lines = append(lines, formula)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
+
+func TestReplSolves(t *testing.T) {
+	stdout, _, code := runMain(t, "a && b\na &&\na && !a\nquit\nb\n", "-repl")
+	for _, want := range []string{"a && b:", "satisfied by", "a &&:", "error:", "a && !a:", "unsatisfiable"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, stdout)
+		}
+	}
+	// A parse error doesn't end the session, but quit does
+	if strings.Contains(stdout, "> b:") {
+		t.Errorf("the line after quit was solved:\n%s", stdout)
+	}
+	if code != exitError {
+		t.Errorf("got exit code %d, want %d", code, exitError)
+	}
+}
+
+func TestParseDefinition(t *testing.T) {
+	tests := []struct {
+		line       string
+		name, body string
+		ok, err    bool
+	}{
+		{"def parity = a ^ b ^ c", "parity", "a ^ b ^ c", true, false},
+		{"def  x=a", "x", "a", true, false},
+		{"a && b", "", "", false, false},
+		{"define = a", "", "", false, false},
+		{"def parity", "", "", true, true},
+		{"def parity =", "", "", true, true},
+		{"def = a", "", "", true, true},
+		{"def true = a", "", "", true, true},
+		{"def xor = a", "", "", true, true},
+		{"def a b = c", "", "", true, true},
+	}
+	for _, tt := range tests {
+		name, body, ok, err := parseDefinition(tt.line)
+		if name != tt.name || body != tt.body || ok != tt.ok || (err != nil) != tt.err {
+			t.Errorf("%q: got %q, %q, %v, %v", tt.line, name, body, ok, err)
+		}
+	}
+}
+
+func TestMacrosFile(t *testing.T) {
+	path := writeFile(t, "macros.txt", "def parity = a ^ b ^ c\n"+
+		"def both = parity && d\n"+
+		"both && !a\n"+
+		"def loop = !loop\n"+
+		"loop\n")
+	stdout, _, code := runMain(t, "", "-json", "-file", path)
+	if code != exitError {
+		t.Errorf("got exit code %d, want %d", code, exitError)
+	}
+	results := decodeResults(t, []byte(stdout))
+	if len(results) != 2 {
+		t.Fatalf("got %v, want a result per formula and none for the definitions", results)
+	}
+	if results[0]["satisfiable"] != true || results[0]["error"] != nil {
+		t.Errorf("both && !a: got %v, want it satisfiable", results[0])
+	}
+	assignment, _ := results[0]["assignment"].(map[string]any)
+	if assignment["a"] != false || assignment["d"] != true || assignment["b"] == assignment["c"] {
+		t.Errorf("both && !a: got %v, which doesn't satisfy it", assignment)
+	}
+	if msg, _ := results[1]["error"].(string); !strings.Contains(msg, "defined in terms of itself") {
+		t.Errorf("loop: got %v, want an error for the cyclic definition", results[1])
+	}
+}
+
+func TestMacrosLabels(t *testing.T) {
+	// The results are labeled with the formulas as written, not expanded
+	path := writeFile(t, "macros.txt", "def both = a && b\nboth && !a\n")
+	stdout, _, _ := runMain(t, "", "-json", "-file", path)
+	results := decodeResults(t, []byte(stdout))
+	if len(results) != 1 || results[0]["formula"] != "both && !a" || results[0]["satisfiable"] != false {
+		t.Errorf("got %v, want both && !a unsatisfiable", results)
+	}
+
+	// Under -rpn the bodies of the macros are postfix too
+	path = writeFile(t, "rpn.txt", "def both = a b &&\nboth a ! &&\nboth b &&\n")
+	stdout, _, _ = runMain(t, "", "-rpn", "-json", "-file", path)
+	results = decodeResults(t, []byte(stdout))
+	if len(results) != 2 {
+		t.Fatalf("got %v, want a result per formula", results)
+	}
+	if results[0]["formula"] != "both a ! &&" || results[0]["satisfiable"] != false {
+		t.Errorf("both a ! &&: got %v, want it unsatisfiable", results[0])
+	}
+	if results[1]["formula"] != "both b &&" || results[1]["satisfiable"] != true {
+		t.Errorf("both b &&: got %v, want it satisfiable", results[1])
+	}
+}
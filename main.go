@@ -1,156 +1,539 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
-	"math"
-	"sync"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"psc-project/pkg/sat"
 )
 
-func evalBoolExpr(expression string, values map[string]bool) (bool, error) {
-	// Parse the boolean expression and create the AST
-	expr, err := parser.ParseExpr(expression)
-	if err != nil {
-		return false, fmt.Errorf("error parsing expression: %v", err)
+// demoFormulas are solved when no other input is given
+var demoFormulas = []string{
+	"a && !a",
+	"a || !a",
+	"a && b || !c",
+	"a && !b",
+	"a && a",
+	"a || b || !b",
+	"a ^ b ^ c",
+	"(a ^ b) && c",
+	"a -> b",
+	"(a -> b) && a && !b",
+	"a -> b -> c",
+	"a <-> !a",
+	"(a <-> b) && a && !b",
+	"a <-> b <-> c",
+	"a && false",
+	"a || true",
+	"true",
+	"false",
+	"true || false",
+}
+
+// Exit codes of the program, from the least to the most severe
+const (
+	exitSatisfiable   = 0 // Every formula is satisfiable
+	exitUnsatisfiable = 1 // At least one formula is unsatisfiable
+	exitError         = 2 // At least one formula couldn't be parsed or solved
+)
+
+// runner solves the formulas and prints their results according to the
+// command line flags
+type runner struct {
+	p       *printer
+	solver  *sat.Solver   // Remembers the formulas already solved
+	timeout time.Duration // Time allowed for each formula, none if zero
+	timing  bool          // Whether to report how long each formula took
+	stats   bool          // Whether to report the work of each search
+	verbose bool          // Whether to print every combination tried
+	symbols []string      // Symbols the formulas range over, theirs if nil
+	quiet   bool          // Whether to only print the unsatisfiable formulas
+	limit   int           // Solutions to list for each formula, if positive
+
+	tally      bool // Whether to end with how many formulas had each outcome
+	progressed bool // Whether the progress of the current search was shown
+
+	satisfiable, unsatisfiable, errors int // Outcomes of the formulas so far
+
+	solved  int           // Formulas timed so far
+	elapsed time.Duration // Total time spent on them
+	status  int           // Exit code for the formulas so far
+}
+
+// fail prints the error for the formula and makes the program exit with
+// exitError
+func (r *runner) fail(label string, err error) {
+	r.p.printError(label, err)
+	r.errors++
+	r.setStatus(exitError)
+}
+
+// record counts the outcome of a formula that could be solved, making the
+// program exit with exitUnsatisfiable if it is unsatisfiable
+func (r *runner) record(satisfiable bool) {
+	if satisfiable {
+		r.satisfiable++
+	} else {
+		r.unsatisfiable++
+		r.setStatus(exitUnsatisfiable)
 	}
+}
 
-	// Create a custom visitor to walk the AST and evaluate the expression
-	evalVisitor := &visitor{values: values}
+// setStatus raises the exit code of the program to status, keeping the most
+// severe one
+func (r *runner) setStatus(status int) {
+	if status > r.status {
+		r.status = status
+	}
+}
 
-	// Walk the AST and evaluate the expression
-	ast.Walk(evalVisitor, expr)
+// context returns the context bounding the solving of a single formula
+func (r *runner) context() (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), r.timeout)
+}
 
-	// Return the final result
-	return evalVisitor.result, nil
+// track reports on the standard error how long the formula took since start,
+// keeping the total for the summary
+func (r *runner) track(label string, start time.Time) {
+	if !r.timing {
+		return
+	}
+	elapsed := time.Since(start)
+	r.solved++
+	r.elapsed += elapsed
+	fmt.Fprintf(os.Stderr, "%s: took %v\n", label, elapsed)
 }
 
-type visitor struct {
-	values map[string]bool // Input values for identifiers
-	result bool            // Final result of the expression
+// showStats reports on the standard error the work of the search of the
+// formula
+func (r *runner) showStats(label string, stats sat.Stats) {
+	stopped := "enumerated all of them"
+	if stats.ShortCircuited {
+		stopped = "stopped early"
+	}
+	variables := "variables"
+	if stats.Variables == 1 {
+		variables = "variable"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d %s, %d of %d (2^%d) combinations evaluated, %s\n",
+		label, stats.Variables, variables, stats.Evaluated, stats.Combinations, stats.Variables, stopped)
 }
 
-func (v *visitor) Visit(node ast.Node) ast.Visitor {
-	if node == nil {
-		// Skip nil nodes
-		return v
+// showProgress reports on the standard error how much of the search of the
+// current formula is done, overwriting the previous report
+func (r *runner) showProgress(explored, total int) {
+	fmt.Fprintf(os.Stderr, "\rsearching: %d of %d combinations (%.1f%%)", explored, total, 100*float64(explored)/float64(total))
+	r.progressed = true
+}
+
+// endProgress ends the line of the progress reports of the search, if any
+func (r *runner) endProgress() {
+	if r.progressed {
+		fmt.Fprintln(os.Stderr)
+		r.progressed = false
 	}
+}
 
-	switch expr := node.(type) {
-	case *ast.Ident:
-		// Check if the identifier exists in the input values
-		value, ok := v.values[expr.Name]
-		if !ok {
-			panic(fmt.Errorf("identifier '%s' not found in input values", expr.Name))
-		}
-		v.result = value
+// summary reports the total time spent on the formulas, and how many of them
+// had each outcome
+func (r *runner) summary() {
+	if r.timing {
+		fmt.Fprintf(os.Stderr, "total: %v for %d formulas\n", r.elapsed, r.solved)
+	}
+	if r.tally {
+		r.p.printTally(r.satisfiable, r.unsatisfiable, r.errors)
+	}
+}
 
-	case *ast.UnaryExpr:
-		// Handle unary expressions (e.g., !c)
-		switch expr.Op {
-		case token.NOT:
-			childVisitor := &visitor{values: v.values}
-			ast.Walk(childVisitor, expr.X)
-			v.result = !childVisitor.result
+// symbolsOf returns the symbols the formula ranges over, the declared ones if
+// any. The solver fails on the ones the formula uses without declaring them
+func (r *runner) symbolsOf(formula string) ([]string, error) {
+	if r.symbols != nil {
+		return r.symbols, nil
+	}
+	return r.solver.ExtractSymbols(formula)
+}
 
-		default:
-			panic(fmt.Errorf("unsupported unary operator: %s", expr.Op))
-		}
+// report solves the formula against its symbols and prints the result under
+// the label
+func (r *runner) report(label, formula string) {
+	symbols, err := r.symbolsOf(formula)
+	if err != nil {
+		r.fail(label, err)
+		return
+	}
+	r.reportSymbols(label, formula, symbols)
+}
 
-	case *ast.BinaryExpr:
-		// Handle binary expressions (e.g., a && b)
-		leftVisitor := &visitor{values: v.values}
-		ast.Walk(leftVisitor, expr.X)
-		rightVisitor := &visitor{values: v.values}
-		ast.Walk(rightVisitor, expr.Y)
+// reportSymbols solves the formula against the given symbols and prints the
+// result under the label
+func (r *runner) reportSymbols(label, formula string, symbols []string) {
+	ctx, cancel := r.context()
+	defer cancel()
 
-		switch expr.Op {
-		case token.LAND:
-			v.result = leftVisitor.result && rightVisitor.result
-		case token.LOR:
-			v.result = leftVisitor.result || rightVisitor.result
-		default:
-			panic(fmt.Errorf("unsupported binary operator: %s", expr.Op))
+	start := time.Now()
+	var result sat.Result
+	var err error
+	if r.verbose {
+		// Follow the search one combination at a time, so that they are
+		// printed in order. Counting them tells the work of the search
+		var stats sat.Stats
+		result, err = r.solver.SolveTrace(ctx, formula, symbols, func(values map[string]bool, res bool) {
+			stats.Variables = len(values)
+			stats.Evaluated++
+			r.p.printTrace(values, res)
+		})
+		if err == nil && r.stats {
+			stats.Combinations = 1 << stats.Variables
+			stats.ShortCircuited = stats.Evaluated < stats.Combinations
+			r.showStats(label, stats)
 		}
+	} else if r.stats {
+		// Cached results would hide the work of the search
+		var stats sat.Stats
+		result, stats, err = r.solver.SolveStats(ctx, formula, symbols)
+		r.endProgress()
+		if err == nil {
+			r.showStats(label, stats)
+		}
+	} else {
+		result, err = r.solver.SolveContext(ctx, formula, symbols)
+		r.endProgress()
+	}
+	r.track(label, start)
+	if err != nil {
+		r.fail(label, err)
+		return
+	}
+	result.Formula = label
+	r.record(result.Satisfiable)
+	if result.Satisfiable && r.quiet {
+		return
+	}
+	r.p.printResult(result)
+}
 
-	case *ast.ParenExpr:
-		// Handle parentheses expressions
-		childVisitor := &visitor{values: v.values}
-		ast.Walk(childVisitor, expr.X)
-		v.result = childVisitor.result
+// reportCount prints how many combinations of the symbols of the formula
+// satisfy it
+func (r *runner) reportCount(label, formula string) {
+	symbols, err := r.symbolsOf(formula)
+	if err != nil {
+		r.fail(label, err)
+		return
+	}
 
-	default:
-		panic(fmt.Errorf("unsupported expression type: %T", node))
+	ctx, cancel := r.context()
+	defer cancel()
+
+	start := time.Now()
+	count, err := r.solver.CountSolutions(ctx, formula, symbols)
+	var model map[string]bool
+	if err == nil && count > 0 && r.p.json {
+		// The JSON results of the satisfiable formulas always hold a model
+		model, err = r.model(ctx, formula, symbols)
+	}
+	r.track(label, start)
+	if err != nil {
+		r.fail(label, err)
+		return
+	}
+	r.record(count > 0)
+	if count > 0 && r.quiet {
+		return
 	}
+	r.p.printCount(label, symbols, count, model)
+}
 
-	return nil // Return nil to skip children nodes
+// model returns the combination of the symbols satisfying the formula that
+// the solver reports, nil if there is none
+func (r *runner) model(ctx context.Context, formula string, symbols []string) (map[string]bool, error) {
+	result, err := r.solver.SolveContext(ctx, formula, symbols)
+	return result.Assignment, err
 }
 
-func worker(i int, symbols []string, formula string, result chan map[string]bool, satisfied chan bool, wg *sync.WaitGroup) {
-	defer wg.Done()
+// reportSolutions prints the first limit combinations of the symbols of the
+// formula that satisfy it
+func (r *runner) reportSolutions(label, formula string) {
+	symbols, err := r.symbolsOf(formula)
+	if err != nil {
+		r.fail(label, err)
+		return
+	}
+
+	ctx, cancel := r.context()
+	defer cancel()
 
-	// Compute the combination
-	values := make(map[string]bool)
-	for j, symbol := range symbols {
-		value := (i>>j)&1 == 1
-		values[symbol] = value
+	start := time.Now()
+	solutions, err := r.solver.FindSolutions(ctx, formula, symbols, r.limit)
+	r.track(label, start)
+	if err != nil {
+		r.fail(label, err)
+		return
+	}
+	r.record(len(solutions) > 0)
+	if len(solutions) > 0 && r.quiet {
+		return
 	}
+	r.p.printSolutions(label, solutions)
+}
 
-	// Evaluate the expression on the computed combination of values
-	res, err := evalBoolExpr(formula, values)
+// reportTable prints the truth table of the formula over its symbols
+func (r *runner) reportTable(label, formula string) {
+	symbols, err := r.symbolsOf(formula)
 	if err != nil {
-		panic(err)
+		r.fail(label, err)
+		return
+	}
+
+	ctx, cancel := r.context()
+	defer cancel()
+
+	start := time.Now()
+	symbols, table, err := r.solver.TruthTable(ctx, formula, symbols)
+	r.track(label, start)
+	if err != nil {
+		r.fail(label, err)
+		return
+	}
+	// The formula is unsatisfiable if no row of its table is true
+	satisfiable := false
+	for _, row := range table {
+		if row[len(row)-1] {
+			satisfiable = true
+			break
+		}
 	}
+	r.record(satisfiable)
+	if satisfiable && r.quiet {
+		return
+	}
+	r.p.printTable(label, symbols, table)
+}
+
+// parseSymbols splits the comma separated list of symbols, ignoring the
+// spaces around them and the empty ones
+func parseSymbols(list string) []string {
+	symbols := []string{}
+	for _, symbol := range strings.Split(list, ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
 
-	// If the evaluation is true, return the result to the channel
-	if res {
-		satisfied <- true
-        result <- values
+// checkModes fails on the flags that are set together but can't be honored
+// together. The tables, counts and lists of solutions don't go through the
+// search that -v and -stats report on, and the tables have no JSON form
+func checkModes(set map[string]bool) error {
+	conflicts := []struct {
+		flag  string
+		modes []string
+	}{
+		{"v", []string{"table", "csv", "markdown", "count", "limit"}},
+		{"stats", []string{"table", "csv", "markdown", "count", "limit"}},
+		{"json", []string{"table", "csv", "markdown"}},
 	}
+	for _, c := range conflicts {
+		for _, mode := range c.modes {
+			if set[c.flag] && set[mode] {
+				return fmt.Errorf("-%s can't be combined with -%s", c.flag, mode)
+			}
+		}
+	}
+	return nil
 }
 
 func main() {
-	formulas := []string{
-		"a && !a",
-		"a || !a",
-		"a && b || !c",
-        "a && !b",
-        "a && a",
-        "a || b || !b",
-	}
-	symbols := []string{"a", "b", "c"}
-
-	for _, formula := range formulas {
-		// For each combination, eval the expression
-		nCombinations := int(math.Pow(2, float64(len(symbols))))
-
-		result := make(chan map[string]bool)
-		satisfied := make(chan bool)
-		var wg sync.WaitGroup
-
-        // Launch worker threads
-		for i := 0; i < nCombinations; i++ {
-			wg.Add(1)
-			go worker(i, symbols, formula, result, satisfied, &wg)
-		}
-
-        // Wait for the workers to finish in a goroutine
-		go func() {
-			wg.Wait()
-			close(result)
-			close(satisfied)
-		}()
-
-        // If the formula is satisfied print the result
-		sat := <-satisfied
-		if sat {
-			resValues := <-result
-            fmt.Printf("\033[97;1m%s\033[0m:\n", formula)
-            fmt.Printf("  └─ \033[32msatisfied\033[0m by %v\n", resValues)
-		} else {
-            fmt.Printf("\033[97;1m%s\033[0m:\n", formula)
-            fmt.Printf("  └─ \033[31munsatisfiable\033[0m\n")
+	os.Exit(run())
+}
+
+// run parses the command line flags, processes the formulas and returns the
+// exit code of the program
+func run() int {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [- | formula...]\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "The formulas given as arguments are solved in place of the demo ones.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "With - or a piped standard input, the formulas are read from it one per line.\n\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "\nExit status:\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  %d  every formula is satisfiable\n", exitSatisfiable)
+		fmt.Fprintf(flag.CommandLine.Output(), "  %d  at least one formula is unsatisfiable\n", exitUnsatisfiable)
+		fmt.Fprintf(flag.CommandLine.Output(), "  %d  at least one formula couldn't be read, parsed or solved\n", exitError)
+	}
+	file := flag.String("file", "", "read the formulas from `path`, one per line")
+	color := flag.Bool("color", os.Getenv("NO_COLOR") == "", "color the output with ANSI escape codes")
+	jsonOutput := flag.Bool("json", false, "print one JSON object per formula")
+	table := flag.Bool("table", false, "print the truth table of each formula")
+	count := flag.Bool("count", false, "print how many assignments satisfy each formula")
+	csvOutput := flag.Bool("csv", false, "print the truth table of each formula as CSV")
+	markdown := flag.Bool("markdown", false, "print the truth table of each formula as a Markdown table")
+	binary := flag.Bool("binary", false, "write CSV cells as 0/1 instead of true/false")
+	out := flag.String("out", "", "write the output to `path` instead of the standard output")
+	dimacs := flag.String("dimacs", "", "solve the DIMACS CNF instance in `path`")
+	foldCase := flag.Bool("fold", false, "treat identifiers differing only in case as the same symbol")
+	rpn := flag.Bool("rpn", false, "read the formulas in postfix notation, as in \"a b && !\"")
+	serve := flag.String("serve", "", "serve POST /solve requests over HTTP on `address`, such as :8080")
+	interactive := flag.Bool("repl", false, "prompt for the formulas one at a time until quit")
+	verbose := flag.Bool("v", false, "print every combination tried, in order, before each result")
+	stats := flag.Bool("stats", false, "report on the standard error the size of each search and the work done")
+	timing := flag.Bool("time", false, "report on the standard error how long each formula took")
+	order := flag.String("order", "", "try and print the comma separated `symbols` first, in order, the first being the most significant")
+	limit := flag.Int("limit", 0, "list the first `n` satisfying assignments of each formula")
+	tally := flag.Bool("summary", false, "end with how many formulas were satisfiable, unsatisfiable or failed")
+	progress := flag.Bool("progress", false, "report on the standard error how much of each search is done")
+	negate := flag.Bool("negate", false, "solve the negation of each formula, unsatisfiable if the formula is valid")
+	quiet := flag.Bool("quiet", false, "only print the unsatisfiable formulas and the errors")
+	declare := flag.String("declare", "", "solve the formulas over the comma separated `symbols` instead of the ones they use")
+	workers := flag.Int("workers", 0, "search each formula with `n` goroutines, 0 for one per CPU")
+	timeout := flag.Duration("timeout", 0, "give up on a formula after `duration`, 0 for no limit")
+	flag.Parse()
+	if err := checkModes(map[string]bool{
+		"table": *table, "csv": *csvOutput, "markdown": *markdown, "count": *count, "limit": *limit > 0,
+		"v": *verbose, "stats": *stats, "json": *jsonOutput,
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	p := &printer{w: os.Stdout, color: *color, json: *jsonOutput, csv: *csvOutput, markdown: *markdown, binary: *binary}
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer f.Close()
+
+		// Escape codes make no sense in a file
+		p.w, p.color = f, false
+	}
+
+	r := &runner{p: p, timeout: *timeout, timing: *timing, stats: *stats, verbose: *verbose, quiet: *quiet, limit: *limit, tally: *tally}
+	// Under -rpn the solver builds the postfix formulas itself, while a
+	// DIMACS instance is still turned into an infix one
+	opts := sat.Options{FoldCase: *foldCase, Postfix: *rpn && *dimacs == "", Workers: *workers}
+	if *progress {
+		opts.Progress = r.showProgress
+	}
+	if *order != "" {
+		opts.Order = parseSymbols(*order)
+	}
+	r.solver = sat.NewSolverWithOptions(opts)
+	p.order = r.solver.Order()
+	if *declare != "" {
+		r.symbols = parseSymbols(*declare)
+	}
+	solve := r.report
+	switch {
+	case *table || *csvOutput || *markdown:
+		solve = r.reportTable
+	case *count:
+		solve = r.reportCount
+	case *limit > 0:
+		solve = r.reportSolutions
+	}
+	if *negate {
+		// The negation is unsatisfiable exactly when the formula is valid
+		positive := solve
+		solve = func(label, formula string) {
+			// Report the errors of the formula as written, not of its
+			// negation
+			if _, err := r.solver.ExtractSymbols(formula); err != nil {
+				r.fail(label, err)
+				return
+			}
+			if *rpn {
+				positive("!("+label+")", formula+" !")
+				return
+			}
+			positive("!("+label+")", "!("+formula+")")
+		}
+	}
+
+	// Under -rpn the formulas and the bodies of the macros are postfix
+	expand := sat.ExpandMacros
+	if *rpn {
+		expand = sat.ExpandMacrosRPN
+	}
+
+	// Definitions can come before the formulas in any input. The results are
+	// labeled with the lines as written
+	macros := make(map[string]string)
+	process := func(line string) {
+		if name, body, ok, err := parseDefinition(line); ok {
+			if err != nil {
+				r.fail(line, err)
+				return
+			}
+			macros[name] = body
+			return
+		}
+		formula, err := expand(line, macros)
+		if err != nil {
+			r.fail(line, err)
+			return
+		}
+		solve(line, formula)
+	}
+
+	switch {
+	case *serve != "":
+		if err := http.ListenAndServe(*serve, newHandler(r.solver, *timeout)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+
+	case *interactive:
+		if err := repl(os.Stdin, p.w, process); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+
+	case *dimacs != "":
+		formula, symbols, err := readDIMACSFile(*dimacs)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		r.reportSymbols(*dimacs, formula, symbols)
+
+	case *file != "":
+		formulas, err := readFormulasFile(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		for _, formula := range formulas {
+			process(formula)
+		}
+
+	case flag.NArg() > 0 && flag.Arg(0) != "-":
+		for _, formula := range flag.Args() {
+			process(formula)
+		}
+
+	case flag.Arg(0) == "-" || stdinIsPiped():
+		// Solve each formula as it comes, so that it works in pipelines
+		if err := scanFormulas(os.Stdin, process); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+
+	default:
+		for _, formula := range demoFormulas {
+			process(formula)
 		}
 	}
+
+	r.summary()
+	return r.status
 }
@@ -0,0 +1,552 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runMainEnv makes the test binary run the program instead of the tests
+const runMainEnv = "PSC_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		os.Exit(run())
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs the program with the arguments and the standard input, none if
+// empty, returning what it printed and its exit code
+func runMain(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), runMainEnv+"=1", "NO_COLOR=1")
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+		want  int
+	}{
+		{"satisfiable", "", []string{"a && b", "a || !a"}, exitSatisfiable},
+		{"unsatisfiable", "", []string{"a && b", "a && !a"}, exitUnsatisfiable},
+		{"error", "", []string{"a &&"}, exitError},
+		{"error over unsatisfiable", "", []string{"a && !a", "a +", "b"}, exitError},
+		{"unknown flag", "", []string{"-nope"}, exitError},
+		{"missing file", "", []string{"-file", "does-not-exist.txt"}, exitError},
+		{"piped", "a\nb && !b\n", nil, exitUnsatisfiable},
+		{"demo", "", nil, exitUnsatisfiable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, stderr, code := runMain(t, test.stdin, test.args...)
+			if code != test.want {
+				t.Errorf("got exit code %d, want %d; stderr: %s", code, test.want, stderr)
+			}
+		})
+	}
+}
+
+func TestUsageExitCodes(t *testing.T) {
+	_, stderr, _ := runMain(t, "", "-h")
+	for _, want := range []string{"Exit status:", "0  every formula is satisfiable", "1  at least one formula is unsatisfiable", "2  at least one formula"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("usage doesn't contain %q:\n%s", want, stderr)
+		}
+	}
+}
+
+func TestFormulaArgs(t *testing.T) {
+	stdout, stderr, code := runMain(t, "", "-json", "a && b", "c || !c", "a && !a")
+	if code != exitUnsatisfiable {
+		t.Errorf("got exit code %d, want %d; stderr: %s", code, exitUnsatisfiable, stderr)
+	}
+
+	// The arguments replace the demo formulas
+	results := decodeResults(t, []byte(stdout))
+	want := []struct {
+		formula     string
+		satisfiable bool
+	}{
+		{"a && b", true},
+		{"c || !c", true},
+		{"a && !a", false},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d:\n%s", len(results), len(want), stdout)
+	}
+	for i, w := range want {
+		if results[i]["formula"] != w.formula || results[i]["satisfiable"] != w.satisfiable {
+			t.Errorf("result %d: got %v, want %s satisfiable %t", i, results[i], w.formula, w.satisfiable)
+		}
+	}
+
+	// Each against the symbols it uses
+	if assignment, _ := results[1]["assignment"].(map[string]any); len(assignment) != 1 || assignment["c"] == nil {
+		t.Errorf("c || !c: got assignment %v, want one over c", results[1]["assignment"])
+	}
+}
+
+func TestVerbose(t *testing.T) {
+	stdout, stderr, code := runMain(t, "", "-v", "a && b")
+	if code != exitSatisfiable {
+		t.Errorf("got exit code %d, want %d; stderr: %s", code, exitSatisfiable, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	want := []string{
+		"{a:false b:false} -> false",
+		"{a:false b:true} -> false",
+		"{a:true b:false} -> false",
+		"{a:true b:true} -> true",
+		"a && b:",
+	}
+	if len(lines) < len(want) || strings.Join(lines[:len(want)], "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant the four combinations in order, then the result", stdout)
+	}
+
+	// The search stops at the first model
+	stdout, _, _ = runMain(t, "", "-v", "a || b")
+	if n := strings.Count(stdout, " -> "); n != 2 {
+		t.Errorf("a || b: got %d combinations, want 2:\n%s", n, stdout)
+	}
+}
+
+func TestRPNFlag(t *testing.T) {
+	stdout, stderr, code := runMain(t, "", "-rpn", "-json", "a b &&", "a a ! &&", "a &&")
+	if code != exitError {
+		t.Errorf("got exit code %d, want %d; stderr: %s", code, exitError, stderr)
+	}
+	results := decodeResults(t, []byte(stdout))
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3:\n%s", len(results), stdout)
+	}
+	if results[0]["satisfiable"] != true || results[1]["satisfiable"] != false || results[2]["error"] == nil {
+		t.Errorf("got %v, want a && b satisfiable, a && !a unsatisfiable and an error", results)
+	}
+
+	// The negation is postfix as well
+	stdout, _, code = runMain(t, "", "-rpn", "-negate", "-json", "a a ! ||")
+	if results := decodeResults(t, []byte(stdout)); code != exitUnsatisfiable || len(results) != 1 || results[0]["satisfiable"] != false {
+		t.Errorf("a a ! ||: got exit code %d and %s, want the negation of a tautology unsatisfiable", code, stdout)
+	}
+}
+
+func TestCountFlag(t *testing.T) {
+	stdout, _, code := runMain(t, "", "-count", "a || b", "exactly(2, a, b, c)", "a && !a")
+	for _, want := range []string{
+		"a || b:\n  └─ 3 of 4 (2^2) assignments satisfy\n",
+		"exactly(2, a, b, c):\n  └─ 3 of 8 (2^3) assignments satisfy\n",
+		"a && !a:\n  └─ 0 of 2 (2^1) assignments satisfy\n",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, stdout)
+		}
+	}
+	if code != exitUnsatisfiable {
+		t.Errorf("got exit code %d, want %d", code, exitUnsatisfiable)
+	}
+
+	stdout, _, _ = runMain(t, "", "-count", "-json", "a || b || c")
+	results := decodeResults(t, []byte(stdout))
+	if len(results) != 1 || results[0]["count"] != 7.0 || results[0]["satisfiable"] != true {
+		t.Errorf("got %v, want a count of 7", results)
+	}
+}
+
+func TestDeclareFlag(t *testing.T) {
+	// An unused symbol doubles the combinations, and the models with them
+	_, stderr, _ := runMain(t, "", "-stats", "a && !a")
+	if !strings.Contains(stderr, "2 of 2 (2^1) combinations") {
+		t.Errorf("without -declare: got %q, want the 2 combinations of a", stderr)
+	}
+	_, stderr, _ = runMain(t, "", "-stats", "-declare", "a,b", "a && !a")
+	if !strings.Contains(stderr, "4 of 4 (2^2) combinations") {
+		t.Errorf("with -declare: got %q, want the 4 combinations of a and b", stderr)
+	}
+	stdout, _, _ := runMain(t, "", "-count", "-json", "a && b")
+	declared, _, _ := runMain(t, "", "-count", "-json", "-declare", "a,b,c", "a && b")
+	without, with := decodeResults(t, []byte(stdout)), decodeResults(t, []byte(declared))
+	if without[0]["count"] != 1.0 || with[0]["count"] != 2.0 {
+		t.Errorf("got counts %v and %v, want 1 and 2", without[0]["count"], with[0]["count"])
+	}
+
+	// The results don't change
+	stdout, _, code := runMain(t, "", "-json", "-declare", "a,b,c", "a && b", "a && !a")
+	results := decodeResults(t, []byte(stdout))
+	if len(results) != 2 || results[0]["satisfiable"] != true || results[1]["satisfiable"] != false {
+		t.Fatalf("got %v, want the first satisfiable and the second not", results)
+	}
+	want := map[string]any{"a": true, "b": true, "c": false}
+	if !reflect.DeepEqual(results[0]["assignment"], want) {
+		t.Errorf("got assignment %v, want %v", results[0]["assignment"], want)
+	}
+	if code != exitUnsatisfiable {
+		t.Errorf("got exit code %d, want %d", code, exitUnsatisfiable)
+	}
+
+	// The formulas can't use the symbols left out
+	stdout, _, code = runMain(t, "", "-declare", "a", "a && b")
+	if code != exitError || !strings.Contains(stdout, "identifier 'b' not found") {
+		t.Errorf("got exit code %d and %q, want an error for b", code, stdout)
+	}
+}
+
+func TestQuietFlag(t *testing.T) {
+	stdout, _, code := runMain(t, "", "-quiet", "a && b", "a && !a", "b || c", "c && !c")
+	want := "a && !a:\n  └─ unsatisfiable\nc && !c:\n  └─ unsatisfiable\n"
+	if stdout != want {
+		t.Errorf("got\n%s\nwant\n%s", stdout, want)
+	}
+	if code != exitUnsatisfiable {
+		t.Errorf("got exit code %d, want %d", code, exitUnsatisfiable)
+	}
+
+	// Still in red
+	stdout, _, _ = runMain(t, "", "-quiet", "-color", "a && !a")
+	if !strings.Contains(stdout, "\x1b[31munsatisfiable\x1b[0m") {
+		t.Errorf("got %q, want unsatisfiable in red", stdout)
+	}
+
+	// Nothing at all when every formula is satisfiable
+	stdout, _, code = runMain(t, "", "-quiet", "a", "b")
+	if stdout != "" || code != exitSatisfiable {
+		t.Errorf("got %q and exit code %d, want no output and %d", stdout, code, exitSatisfiable)
+	}
+}
+
+func TestNegateFlag(t *testing.T) {
+	stdout, _, code := runMain(t, "", "-negate", "-json", "a || !a", "a && b")
+	results := decodeResults(t, []byte(stdout))
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2:\n%s", len(results), stdout)
+	}
+	// The negation of a tautology is unsatisfiable
+	if results[0]["formula"] != "!(a || !a)" || results[0]["satisfiable"] != false {
+		t.Errorf("got %v, want !(a || !a) unsatisfiable", results[0])
+	}
+	// That of another formula is satisfied by a counterexample
+	if results[1]["formula"] != "!(a && b)" || results[1]["satisfiable"] != true {
+		t.Errorf("got %v, want !(a && b) satisfiable", results[1])
+	}
+	if code != exitUnsatisfiable {
+		t.Errorf("got exit code %d, want %d", code, exitUnsatisfiable)
+	}
+
+	// Every formula is valid exactly when the exit code is 1
+	_, _, code = runMain(t, "", "-negate", "a -> a", "a || b || !b")
+	if code != exitUnsatisfiable {
+		t.Errorf("tautologies: got exit code %d, want %d", code, exitUnsatisfiable)
+	}
+
+	// The errors point into the formula as written, not into its negation
+	stdout, _, code = runMain(t, "", "-negate", "-json", "a &&")
+	results = decodeResults(t, []byte(stdout))
+	if len(results) != 1 || results[0]["formula"] != "a &&" || !strings.Contains(fmt.Sprint(results[0]["error"]), "near column 5:") {
+		t.Errorf("got %v, want an error on a && near column 5", results)
+	}
+	if code != exitError {
+		t.Errorf("invalid formula: got exit code %d, want %d", code, exitError)
+	}
+}
+
+func TestProgressFlag(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the search takes a second")
+	}
+	// A contradiction over enough symbols to outlast a few reports
+	symbols := make([]string, 21)
+	for i := range symbols {
+		symbols[i] = "s" + strconv.Itoa(i)
+	}
+	declare := strings.Join(symbols, ",")
+
+	stdout, stderr, _ := runMain(t, "", "-progress", "-declare", declare, "s0 && !s0")
+	if !strings.Contains(stderr, "searching: ") || !strings.Contains(stderr, " of 2097152 combinations (") {
+		t.Errorf("got stderr %q, want the progress of the search", stderr)
+	}
+	// The line of the reports is ended before the result
+	if !strings.HasSuffix(stderr, "\n") {
+		t.Errorf("got stderr %q, want it to end with a newline", stderr)
+	}
+	if strings.Contains(stdout, "searching") || stdout != "s0 && !s0:\n  └─ unsatisfiable\n" {
+		t.Errorf("got stdout %q, want only the result", stdout)
+	}
+
+	if _, stderr, _ := runMain(t, "", "-declare", declare, "s0 && !s0"); stderr != "" {
+		t.Errorf("without -progress: got stderr %q, want none", stderr)
+	}
+}
+
+func TestSummaryFlag(t *testing.T) {
+	stdin := "a || b\na && !a\na +\nb -> c\nc && !c\n"
+	stdout, _, code := runMain(t, stdin, "-summary")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if want := "2 satisfiable, 2 unsatisfiable, 1 error"; lines[len(lines)-1] != want {
+		t.Errorf("got summary %q, want %q", lines[len(lines)-1], want)
+	}
+	if code != exitError {
+		t.Errorf("got exit code %d, want %d", code, exitError)
+	}
+
+	stdout, _, _ = runMain(t, stdin, "-summary", "-json")
+	results := decodeResults(t, []byte(stdout))
+	tally := results[len(results)-1]
+	if tally["satisfiable"] != 2.0 || tally["unsatisfiable"] != 2.0 || tally["errors"] != 1.0 {
+		t.Errorf("got summary %v, want 2 satisfiable, 2 unsatisfiable and 1 error", tally)
+	}
+	if len(results) != 6 {
+		t.Errorf("got %d lines of JSON, want a result per formula and the summary", len(results))
+	}
+
+	if stdout, _, _ := runMain(t, stdin); strings.Contains(stdout, "satisfiable, ") {
+		t.Errorf("printed a summary without -summary:\n%s", stdout)
+	}
+}
+
+func TestTimeoutFlag(t *testing.T) {
+	// Every mode gives up on a formula that would take far longer
+	for _, mode := range [][]string{{}, {"-count"}, {"-limit", "1"}, {"-table"}} {
+		args := append(append([]string{"-timeout", "50ms"}, mode...), contradiction(40))
+		stdout, stderr, code := runMain(t, "", args...)
+		if !strings.Contains(stdout, "timeout") {
+			t.Errorf("%v: output doesn't report the timeout:\n%s", mode, stdout)
+		}
+		if code != exitError {
+			t.Errorf("%v: got exit code %d, want %d; stderr: %s", mode, code, exitError, stderr)
+		}
+	}
+}
+
+func TestLimitFlag(t *testing.T) {
+	stdout, _, code := runMain(t, "", "-limit", "2", "a || b || c")
+	want := "a || b || c:\n" +
+		"  ├─ satisfied by map[a:false b:false c:true]\n" +
+		"  └─ satisfied by map[a:false b:true c:false]\n"
+	if !strings.Contains(stdout, want) {
+		t.Errorf("output doesn't contain %q:\n%s", want, stdout)
+	}
+	if code != exitSatisfiable {
+		t.Errorf("got exit code %d, want %d", code, exitSatisfiable)
+	}
+
+	stdout, _, code = runMain(t, "", "-limit", "2", "-json", "a || b || c", "a && !a")
+	results := decodeResults(t, []byte(stdout))
+	if len(results) != 2 {
+		t.Fatalf("got %v, want a result per formula", results)
+	}
+	solutions, _ := results[0]["solutions"].([]any)
+	if len(solutions) != 2 || !reflect.DeepEqual(solutions[0], results[0]["assignment"]) {
+		t.Errorf("a || b || c: got %v, want 2 solutions starting with the assignment", results[0])
+	}
+	if results[1]["satisfiable"] != false || results[1]["solutions"] != nil {
+		t.Errorf("a && !a: got %v, want no solution", results[1])
+	}
+	if code != exitUnsatisfiable {
+		t.Errorf("got exit code %d, want %d", code, exitUnsatisfiable)
+	}
+}
+
+func TestOrderFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "  └─ satisfied by map[a:false b:false c:true]\n"},
+		{[]string{"-order", "c,a,b"}, "  └─ satisfied by map[c:false a:false b:true]\n"},
+		// The symbols left out come after the ones given, sorted
+		{[]string{"-order", "b"}, "  └─ satisfied by map[b:false a:false c:true]\n"},
+		{[]string{"-order", "c,a", "-limit", "3"}, "  ├─ satisfied by map[c:false a:false b:true]\n" +
+			"  ├─ satisfied by map[c:false a:true b:false]\n" +
+			"  └─ satisfied by map[c:false a:true b:true]\n"},
+	}
+	for _, tt := range tests {
+		stdout, _, code := runMain(t, "", append(tt.args, "a || b || c")...)
+		if want := "a || b || c:\n" + tt.want; !strings.Contains(stdout, want) {
+			t.Errorf("%v: output doesn't contain %q:\n%s", tt.args, want, stdout)
+		}
+		if code != exitSatisfiable {
+			t.Errorf("%v: got exit code %d, want %d", tt.args, code, exitSatisfiable)
+		}
+	}
+}
+
+func TestOrderFlagTable(t *testing.T) {
+	// The default order is the alphabetical one, so spelling it out changes
+	// neither the table nor the trace
+	for _, flag := range []string{"-table", "-csv", "-v"} {
+		want, _, _ := runMain(t, "", flag, "b || !a")
+		got, _, _ := runMain(t, "", "-order", "a,b", flag, "b || !a")
+		if got != want {
+			t.Errorf("%s: got\n%s\nwith the default order spelled out, want\n%s", flag, got, want)
+		}
+	}
+
+	// The rows follow the lexicographic order, the first symbol being the
+	// most significant
+	stdout, _, _ := runMain(t, "", "-csv", "-order", "b", "a && !b")
+	want := "b,a,result\nfalse,false,false\nfalse,true,true\ntrue,false,false\ntrue,true,false\n"
+	if !strings.Contains(stdout, want) {
+		t.Errorf("got\n%s\nwant the rows\n%s", stdout, want)
+	}
+}
+
+func TestStatsFlag(t *testing.T) {
+	formulas := []string{"a || b || c", "a && !a", "s0 -> s1 && s2 || s3", "!(a ^ b) && a && !b"}
+	_, stderr, _ := runMain(t, "", append([]string{"-stats"}, formulas...)...)
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if len(lines) != len(formulas) {
+		t.Fatalf("got %q, want a line per formula", lines)
+	}
+	for i, line := range lines {
+		label, stats, _ := strings.Cut(line, ": ")
+		if label != formulas[i] {
+			t.Errorf("got %q, want the stats of %s", line, formulas[i])
+		}
+		var n, evaluated, total, exponent int
+		var variables string
+		if _, err := fmt.Sscanf(stats, "%d %s %d of %d (2^%d) combinations evaluated", &n, &variables, &evaluated, &total, &exponent); err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		if want := "variables,"; n == 1 && variables != "variable," || n != 1 && variables != want {
+			t.Errorf("%q: got %q for %d variables", line, variables, n)
+		}
+		if n != exponent || total != 1<<n {
+			t.Errorf("%s: got %d combinations of %d variables, want 2^%d", formulas[i], total, n, n)
+		}
+		if evaluated < 1 || evaluated > total {
+			t.Errorf("%s: evaluated %d of %d combinations", formulas[i], evaluated, total)
+		}
+		stopped := evaluated < total
+		if strings.HasSuffix(stats, "stopped early") != stopped {
+			t.Errorf("%s: got %q, which contradicts the count", formulas[i], stats)
+		}
+	}
+	if !strings.HasSuffix(lines[1], "2 of 2 (2^1) combinations evaluated, enumerated all of them") {
+		t.Errorf("a && !a: got %q, want all the combinations evaluated", lines[1])
+	}
+}
+
+func TestStatsFlagTrace(t *testing.T) {
+	// The trace is the search, so it is reported on as well
+	stdout, stderr, _ := runMain(t, "", "-v", "-stats", "a || b")
+	if !strings.Contains(stdout, "{a:false b:true} -> true") {
+		t.Errorf("got\n%s\nwant the trace", stdout)
+	}
+	if want := "a || b: 2 variables, 2 of 4 (2^2) combinations evaluated, stopped early\n"; stderr != want {
+		t.Errorf("got %q, want %q", stderr, want)
+	}
+}
+
+func TestConflictingFlags(t *testing.T) {
+	// The flags that would be silently ignored are refused before reading
+	// any formula
+	tests := [][]string{
+		{"-count", "-stats"},
+		{"-limit", "2", "-stats"},
+		{"-table", "-stats"},
+		{"-limit", "2", "-v"},
+		{"-csv", "-v"},
+		{"-table", "-json"},
+		{"-markdown", "-json"},
+	}
+	for _, args := range tests {
+		stdout, stderr, code := runMain(t, "", append(args, "a || b")...)
+		if code != exitError {
+			t.Errorf("%v: got exit code %d, want %d", args, code, exitError)
+		}
+		if stdout != "" || !strings.Contains(stderr, "can't be combined with") {
+			t.Errorf("%v: got %q and %q, want only an error", args, stdout, stderr)
+		}
+	}
+}
+
+func TestSymbolsPerFormula(t *testing.T) {
+	// Each formula is solved on its own symbols, whichever they are
+	stdout, _, _ := runMain(t, "", "-json", "d && !a", "zeta || !zeta", "true || false")
+	results := decodeResults(t, []byte(stdout))
+	want := []map[string]any{
+		{"a": false, "d": true},
+		{"zeta": false},
+		{},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %v, want a result per formula", results)
+	}
+	for i, result := range results {
+		if assignment, _ := result["assignment"].(map[string]any); !reflect.DeepEqual(assignment, want[i]) {
+			t.Errorf("%s: got assignment %v, want %v", result["formula"], result["assignment"], want[i])
+		}
+	}
+}
+
+func TestStdin(t *testing.T) {
+	// An invalid line is reported without stopping at it
+	stdin := "a && b\na &&\n\nb || !b"
+	for _, args := range [][]string{{"-json"}, {"-json", "-"}} {
+		stdout, _, code := runMain(t, stdin, args...)
+		results := decodeResults(t, []byte(stdout))
+		var formulas []string
+		for _, result := range results {
+			formula, _ := result["formula"].(string)
+			formulas = append(formulas, formula)
+		}
+		if want := []string{"a && b", "a &&", "b || !b"}; !reflect.DeepEqual(formulas, want) {
+			t.Errorf("%v: solved %q, want %q", args, formulas, want)
+		}
+		if len(results) == 3 && (results[1]["error"] == nil || results[2]["satisfiable"] != true) {
+			t.Errorf("%v: got %v, want an error for the second formula only", args, results)
+		}
+		if code != exitError {
+			t.Errorf("%v: got exit code %d, want %d", args, code, exitError)
+		}
+	}
+}
+
+func TestTimeFlag(t *testing.T) {
+	formulas := []string{"a || b", "a && !a", "a +"}
+	stdout, stderr, _ := runMain(t, "", append([]string{"-time"}, formulas...)...)
+	// The formulas failing to parse are never solved, so they aren't timed
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %q, want a line per valid formula and the total", lines)
+	}
+	for i, formula := range formulas[:2] {
+		took, ok := strings.CutPrefix(lines[i], formula+": took ")
+		if _, err := time.ParseDuration(took); !ok || err != nil {
+			t.Errorf("got %q, want how long %s took", lines[i], formula)
+		}
+	}
+	total, ok := strings.CutPrefix(lines[len(lines)-1], "total: ")
+	total, ok2 := strings.CutSuffix(total, " for 2 formulas")
+	if _, err := time.ParseDuration(total); !ok || !ok2 || err != nil {
+		t.Errorf("got %q, want the total time of the 2 valid formulas", lines[len(lines)-1])
+	}
+
+	// The timing doesn't change the results
+	plain, _, _ := runMain(t, "", formulas...)
+	if stdout != plain {
+		t.Errorf("got\n%s\nwant the output without -time\n%s", stdout, plain)
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"psc-project/pkg/sat"
+)
+
+// printer writes the results of the formulas in a human readable form, or
+// as one JSON object per formula
+type printer struct {
+	w        io.Writer // Where the results are written
+	color    bool      // Whether to use ANSI escape codes
+	json     bool      // Whether to print JSON instead
+	csv      bool      // Whether to print truth tables as CSV
+	markdown bool      // Whether to print truth tables as Markdown
+	order    []string  // Symbols printed first in the assignments, in order
+	binary   bool      // Whether CSV cells are 0/1 rather than true/false
+}
+
+// jsonResult is the JSON form of the result of a formula
+type jsonResult struct {
+	sat.Result
+	Count     *int              `json:"count,omitempty"`     // Only set when counting the models
+	Solutions []map[string]bool `json:"solutions,omitempty"` // Only set when listing the models
+	Error     string            `json:"error,omitempty"`
+}
+
+// jsonTally is the JSON form of the outcomes of the formulas
+type jsonTally struct {
+	Satisfiable   int `json:"satisfiable"`
+	Unsatisfiable int `json:"unsatisfiable"`
+	Errors        int `json:"errors"`
+}
+
+// paint wraps s in the ANSI escape code, if colors are enabled
+func (p *printer) paint(code, s string) string {
+	if !p.color {
+		return s
+	}
+	return "\033[" + code + "m" + s + "\033[0m"
+}
+
+func (p *printer) bold(s string) string  { return p.paint("97;1", s) }
+func (p *printer) green(s string) string { return p.paint("32", s) }
+func (p *printer) red(s string) string   { return p.paint("31", s) }
+
+// printResult reports the combination satisfying the formula, or that it is
+// unsatisfiable
+func (p *printer) printResult(result sat.Result) {
+	if p.json {
+		p.printJSON(jsonResult{Result: result})
+		return
+	}
+
+	fmt.Fprintf(p.w, "%s:\n", p.bold(result.Formula))
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(p.w, "  ├─ warning: %s\n", warning)
+	}
+	switch {
+	case !result.Satisfiable:
+		fmt.Fprintf(p.w, "  └─ %s\n", p.red("unsatisfiable"))
+	case len(result.Assignment) == 0:
+		// Formulas made of constants only have nothing to assign
+		fmt.Fprintf(p.w, "  └─ %s\n", p.green("satisfied"))
+	default:
+		fmt.Fprintf(p.w, "  └─ %s by %s\n", p.green("satisfied"), p.formatAssignment(result.Assignment))
+	}
+}
+
+// formatAssignment renders the assignment like a map, with the symbols of
+// the order first and then the others sorted
+func (p *printer) formatAssignment(assignment map[string]bool) string {
+	return "map[" + strings.Join(p.pairs(assignment), " ") + "]"
+}
+
+// pairs renders each value of the assignment as symbol:value, with the
+// symbols of the order first and then the others sorted
+func (p *printer) pairs(assignment map[string]bool) []string {
+	var pairs []string
+	ordered := make(map[string]bool, len(p.order))
+	for _, symbol := range p.order {
+		if value, ok := assignment[symbol]; ok && !ordered[symbol] {
+			pairs = append(pairs, fmt.Sprintf("%s:%t", symbol, value))
+		}
+		ordered[symbol] = true
+	}
+	var rest []string
+	for symbol := range assignment {
+		if !ordered[symbol] {
+			rest = append(rest, symbol)
+		}
+	}
+	sort.Strings(rest)
+	for _, symbol := range rest {
+		pairs = append(pairs, fmt.Sprintf("%s:%t", symbol, assignment[symbol]))
+	}
+	return pairs
+}
+
+// printTrace reports a combination tried for a formula and its result, in
+// the human readable form only
+func (p *printer) printTrace(values map[string]bool, res bool) {
+	if p.json {
+		return
+	}
+	fmt.Fprintf(p.w, "{%s} -> %t\n", strings.Join(p.pairs(values), " "), res)
+}
+
+// printSolutions lists the combinations satisfying the formula, or reports
+// that it is unsatisfiable if there are none
+func (p *printer) printSolutions(formula string, solutions []map[string]bool) {
+	if p.json {
+		// Like the other satisfiable results, these hold their first model
+		result := sat.Result{Formula: formula, Satisfiable: len(solutions) > 0}
+		if result.Satisfiable {
+			result.Assignment = solutions[0]
+		}
+		p.printJSON(jsonResult{Result: result, Solutions: solutions})
+		return
+	}
+
+	fmt.Fprintf(p.w, "%s:\n", p.bold(formula))
+	if len(solutions) == 0 {
+		fmt.Fprintf(p.w, "  └─ %s\n", p.red("unsatisfiable"))
+		return
+	}
+	for i, solution := range solutions {
+		branch := "├─"
+		if i == len(solutions)-1 {
+			branch = "└─"
+		}
+		if len(solution) == 0 {
+			// Formulas made of constants only have nothing to assign
+			fmt.Fprintf(p.w, "  %s %s\n", branch, p.green("satisfied"))
+		} else {
+			fmt.Fprintf(p.w, "  %s %s by %s\n", branch, p.green("satisfied"), p.formatAssignment(solution))
+		}
+	}
+}
+
+// printCount reports how many combinations of the symbols satisfy the
+// formula. The model, nil if there is none, is only part of the JSON form
+func (p *printer) printCount(formula string, symbols []string, count int, model map[string]bool) {
+	if p.json {
+		p.printJSON(jsonResult{Result: sat.Result{Formula: formula, Satisfiable: count > 0, Assignment: model}, Count: &count})
+		return
+	}
+
+	fmt.Fprintf(p.w, "%s:\n", p.bold(formula))
+	total := fmt.Sprintf("%d of %d (2^%d)", count, 1<<len(symbols), len(symbols))
+	if count > 0 {
+		total = p.green(total)
+	} else {
+		total = p.red(total)
+	}
+	fmt.Fprintf(p.w, "  └─ %s assignments satisfy\n", total)
+}
+
+// printError reports that the formula couldn't be solved, or that it took
+// too long to
+func (p *printer) printError(formula string, err error) {
+	timeout := errors.Is(err, context.DeadlineExceeded)
+	if p.json {
+		message := err.Error()
+		if timeout {
+			message = "timeout"
+		}
+		p.printJSON(jsonResult{Result: sat.Result{Formula: formula}, Error: message})
+		return
+	}
+
+	fmt.Fprintf(p.w, "%s:\n", p.bold(formula))
+	if timeout {
+		fmt.Fprintf(p.w, "  └─ %s\n", p.red("timeout"))
+	} else {
+		fmt.Fprintf(p.w, "  └─ %s: %v\n", p.red("error"), err)
+	}
+}
+
+// printTally reports how many formulas had each outcome, on a single line
+func (p *printer) printTally(satisfiable, unsatisfiable, errors int) {
+	if p.json {
+		// The tally only holds integers, so encoding can't fail
+		_ = json.NewEncoder(p.w).Encode(jsonTally{Satisfiable: satisfiable, Unsatisfiable: unsatisfiable, Errors: errors})
+		return
+	}
+
+	errorsLabel := "errors"
+	if errors == 1 {
+		errorsLabel = "error"
+	}
+	fmt.Fprintf(p.w, "%d satisfiable, %d unsatisfiable, %d %s\n", satisfiable, unsatisfiable, errors, errorsLabel)
+}
+
+// printJSON writes the result as a single line of JSON
+func (p *printer) printJSON(result jsonResult) {
+	// Keep the operators readable instead of escaping them as HTML
+	enc := json.NewEncoder(p.w)
+	enc.SetEscapeHTML(false)
+
+	// The result only holds strings and booleans, so encoding can't fail
+	_ = enc.Encode(result)
+}
+
+// printTable renders the truth table of the formula with a column per symbol
+// and the result in the last one
+func (p *printer) printTable(formula string, symbols []string, table [][]bool) {
+	if p.csv {
+		if err := writeCSV(p.w, symbols, table, p.binary); err != nil {
+			p.printError(formula, err)
+		}
+		return
+	}
+	if p.markdown {
+		writeMarkdown(p.w, formula, symbols, table)
+		return
+	}
+
+	headers := append(append([]string{}, symbols...), "result")
+
+	// Every column is wide enough for its header and for "false"
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = len("false")
+		if len(header) > widths[i] {
+			widths[i] = len(header)
+		}
+	}
+
+	fmt.Fprintf(p.w, "%s:\n", p.bold(formula))
+	cells := make([]string, len(headers))
+	for i, header := range headers {
+		cells[i] = fmt.Sprintf("%-*s", widths[i], header)
+	}
+	fmt.Fprintf(p.w, "  %s\n", strings.Join(cells, " │ "))
+	for i := range cells {
+		cells[i] = strings.Repeat("─", widths[i])
+	}
+	fmt.Fprintf(p.w, "  %s\n", strings.Join(cells, "─┼─"))
+
+	for _, row := range table {
+		for i, value := range row {
+			cells[i] = fmt.Sprintf("%-*t", widths[i], value)
+		}
+		// Color the result so that the satisfying rows stand out
+		last := len(row) - 1
+		if row[last] {
+			cells[last] = p.green(cells[last])
+		} else {
+			cells[last] = p.red(cells[last])
+		}
+		fmt.Fprintf(p.w, "  %s\n", strings.Join(cells, " │ "))
+	}
+}
+
+// writeMarkdown writes the truth table as a Markdown table below the formula,
+// followed by a blank line to keep the tables of the formulas apart
+func writeMarkdown(w io.Writer, formula string, symbols []string, table [][]bool) {
+	headers := append(append([]string{}, symbols...), "result")
+	fmt.Fprintf(w, "`%s`:\n\n", formula)
+	fmt.Fprintf(w, "| %s |\n", strings.Join(headers, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(headers)))
+
+	cells := make([]string, len(headers))
+	for _, row := range table {
+		for i, value := range row {
+			cells[i] = strconv.FormatBool(value)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+	fmt.Fprintln(w)
+}
+
+// writeCSV writes the truth table as CSV, with a header naming the symbols and
+// the result column. Cells are either true/false or, if binary, 0/1
+func writeCSV(w io.Writer, symbols []string, table [][]bool, binary bool) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(append([]string{}, symbols...), "result")); err != nil {
+		return err
+	}
+
+	record := make([]string, len(symbols)+1)
+	for _, row := range table {
+		for i, value := range row {
+			switch {
+			case !binary:
+				record[i] = strconv.FormatBool(value)
+			case value:
+				record[i] = "1"
+			default:
+				record[i] = "0"
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"psc-project/pkg/sat"
+)
+
+// decodeResults decodes the JSON results written one per line
+func decodeResults(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+	var results []map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var result map[string]any
+		if err := dec.Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func TestPrintSolutionsJSONAssignment(t *testing.T) {
+	var buf bytes.Buffer
+	p := &printer{w: &buf, json: true}
+	p.printSolutions("a || b", []map[string]bool{{"a": false, "b": true}, {"a": true, "b": false}})
+	p.printSolutions("a && !a", nil)
+
+	results := decodeResults(t, buf.Bytes())
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	want := map[string]any{"a": false, "b": true}
+	if got, ok := results[0]["assignment"].(map[string]any); !ok || len(got) != 2 || got["a"] != want["a"] || got["b"] != want["b"] {
+		t.Errorf("satisfiable: got assignment %v, want the first solution %v", results[0]["assignment"], want)
+	}
+	if results[1]["assignment"] != nil {
+		t.Errorf("unsatisfiable: got assignment %v, want null", results[1]["assignment"])
+	}
+}
+
+func TestPrintCountJSONAssignment(t *testing.T) {
+	var buf bytes.Buffer
+	p := &printer{w: &buf, json: true}
+	p.printCount("a || b", []string{"a", "b"}, 3, map[string]bool{"a": false, "b": true})
+	p.printCount("a && !a", []string{"a"}, 0, nil)
+
+	results := decodeResults(t, buf.Bytes())
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if results[0]["assignment"] == nil || results[0]["count"] != 3.0 {
+		t.Errorf("satisfiable: got %v, want a count of 3 and an assignment", results[0])
+	}
+	if results[1]["assignment"] != nil || results[1]["count"] != 0.0 {
+		t.Errorf("unsatisfiable: got %v, want a count of 0 and a null assignment", results[1])
+	}
+}
+
+func TestFormatAssignmentOrder(t *testing.T) {
+	assignment := map[string]bool{"a": true, "b": false, "c": true, "d": false}
+	tests := []struct {
+		order []string
+		want  string
+	}{
+		{nil, "map[a:true b:false c:true d:false]"},
+		{[]string{"c", "a"}, "map[c:true a:true b:false d:false]"},
+		{[]string{"d", "x", "d", "b"}, "map[d:false b:false a:true c:true]"},
+	}
+	for _, test := range tests {
+		p := &printer{order: test.order}
+		if got := p.formatAssignment(assignment); got != test.want {
+			t.Errorf("order %v: got %s, want %s", test.order, got, test.want)
+		}
+	}
+}
+
+// printAll prints a result of every kind with the printer
+func printAll(p *printer) {
+	p.printResult(sat.Result{Formula: "a", Satisfiable: true, Assignment: map[string]bool{"a": true}})
+	p.printResult(sat.Result{Formula: "a && !a"})
+	p.printError("a &&", errors.New("error parsing expression"))
+	p.printTable("a", []string{"a"}, [][]bool{{false, false}, {true, true}})
+	p.printCount("a", []string{"a"}, 1, nil)
+}
+
+func TestPrinterColor(t *testing.T) {
+	var buf bytes.Buffer
+	printAll(&printer{w: &buf, color: false})
+	if bytes.Contains(buf.Bytes(), []byte("\033")) {
+		t.Errorf("got escape codes with color off:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	printAll(&printer{w: &buf, color: true})
+	if !bytes.Contains(buf.Bytes(), []byte("\033[32m")) || !bytes.Contains(buf.Bytes(), []byte("\033[31m")) {
+		t.Errorf("got no escape codes with color on:\n%s", buf.String())
+	}
+}
+
+func TestPrintResultJSON(t *testing.T) {
+	var buf bytes.Buffer
+	p := &printer{w: &buf, json: true}
+	p.printResult(sat.Result{Formula: "a -> b && !c", Satisfiable: true, Assignment: map[string]bool{"a": false, "b": false, "c": false}})
+	p.printResult(sat.Result{Formula: "a && !a"})
+	p.printError("a &&", errors.New("error parsing expression"))
+
+	output := buf.String()
+	var results []jsonResult
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var result jsonResult
+		if err := dec.Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+
+	if got := results[0]; got.Formula != "a -> b && !c" || !got.Satisfiable || len(got.Assignment) != 3 || got.Error != "" {
+		t.Errorf("satisfiable: got %+v", got)
+	}
+	if got := results[1]; got.Formula != "a && !a" || got.Satisfiable || got.Assignment != nil {
+		t.Errorf("unsatisfiable: got %+v", got)
+	}
+	if got := results[2]; got.Formula != "a &&" || got.Error != "error parsing expression" {
+		t.Errorf("error: got %+v", got)
+	}
+	// The operators are written as they are
+	if !strings.Contains(output, `"a -> b && !c"`) {
+		t.Errorf("got escaped operators:\n%s", output)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	table, err := sat.TruthTable("a && b || c", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, binary := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := writeCSV(&buf, symbols, table, binary); err != nil {
+			t.Fatal(err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1+1<<len(symbols) {
+			t.Fatalf("binary %t: got %d records, want a header and %d rows", binary, len(records), 1<<len(symbols))
+		}
+		if want := []string{"a", "b", "c", "result"}; !reflect.DeepEqual(records[0], want) {
+			t.Errorf("binary %t: got header %q, want %q", binary, records[0], want)
+		}
+		// The rows follow the lexicographic order, a being the highest bit
+		want := []string{"false", "true", "true", "true"}
+		if binary {
+			want = []string{"0", "1", "1", "1"}
+		}
+		if !reflect.DeepEqual(records[4], want) {
+			t.Errorf("binary %t: got row %q, want %q", binary, records[4], want)
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	symbols := []string{"a", "b"}
+	table, err := sat.TruthTable("a && !b", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	writeMarkdown(&buf, "a && !b", symbols, table)
+
+	want := "`a && !b`:\n" +
+		"\n" +
+		"| a | b | result |\n" +
+		"| --- | --- | --- |\n" +
+		"| false | false | false |\n" +
+		"| false | true | false |\n" +
+		"| true | false | true |\n" +
+		"| true | true | false |\n" +
+		"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Every row of the table has the cells of the header
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")[2:]
+	rows := 0
+	for _, line := range lines[2:] {
+		if strings.Count(line, "|") != strings.Count(lines[0], "|") {
+			t.Errorf("row %q doesn't match the header %q", line, lines[0])
+		}
+		rows++
+	}
+	if rows != 4 {
+		t.Errorf("got %d rows, want 4", rows)
+	}
+}
@@ -0,0 +1,544 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"sort"
+)
+
+// IsTautology reports whether the formula is satisfied by every combination
+// of the symbols. When it isn't, the first falsifying combination in the
+// lexicographic order of Solve is returned
+func IsTautology(formula string, symbols []string) (bool, map[string]bool, error) {
+	valid, counterexample, _, err := isTautology(formula, symbols)
+	return valid, counterexample, err
+}
+
+// isTautology is IsTautology, also reporting the work of its search
+func isTautology(formula string, symbols []string) (bool, map[string]bool, Stats, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return false, nil, Stats{}, err
+	}
+
+	// Report the errors of the formula as written, not of its negation
+	if err := checkFormulas(formula); err != nil {
+		return false, nil, Stats{}, err
+	}
+
+	// A tautology is a formula whose negation can't be satisfied
+	counterexample, stats, err := searchStats(context.Background(), "!("+formula+")", lexOrder(symbols), Options{})
+	if err != nil {
+		return false, nil, Stats{}, err
+	}
+
+	return counterexample == nil, counterexample, stats, nil
+}
+
+// IsContradiction reports whether no combination of the symbols satisfies the
+// formula. When one does, the first satisfying combination in the
+// lexicographic order of Solve is returned, which is the model of Solve
+func IsContradiction(formula string, symbols []string) (bool, map[string]bool, error) {
+	contradiction, model, _, err := isContradiction(formula, symbols)
+	return contradiction, model, err
+}
+
+// isContradiction is IsContradiction, also reporting the work of its search
+func isContradiction(formula string, symbols []string) (bool, map[string]bool, Stats, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return false, nil, Stats{}, err
+	}
+
+	// The workers stop as soon as some combination settles the answer
+	model, stats, err := searchStats(context.Background(), formula, lexOrder(symbols), Options{})
+	if err != nil {
+		return false, nil, Stats{}, err
+	}
+
+	return model == nil, model, stats, nil
+}
+
+// FindAllSolutions returns every combination of the symbols that satisfies
+// the formula, in the lexicographic order of Solve, so that the first one is
+// the model Solve returns
+func FindAllSolutions(formula string, symbols []string) ([]map[string]bool, error) {
+	return FindSolutions(formula, symbols, 0)
+}
+
+// FindSolutions is like FindAllSolutions, but stops at the first limit
+// solutions, unless the limit is not positive
+func FindSolutions(formula string, symbols []string, limit int) ([]map[string]bool, error) {
+	return findSolutions(context.Background(), formula, symbols, limit, Options{})
+}
+
+// findSolutions is FindSolutions with the options, giving up with the context
+// error once the context is done
+func findSolutions(ctx context.Context, formula string, symbols []string, limit int, opts Options) ([]map[string]bool, error) {
+	symbols, _, err := checkRepeated(symbols, opts.Strict)
+	if err != nil {
+		return nil, err
+	}
+
+	var solutions []map[string]bool
+	err = forEachCombination(ctx, formula, bitOrder(symbols, opts.Order), opts, func(values map[string]bool, res bool) bool {
+		if res {
+			solutions = append(solutions, copyValues(values))
+		}
+		return limit <= 0 || len(solutions) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return solutions, nil
+}
+
+// SolutionsChan streams the combinations of the symbols that satisfy the
+// formula, in the lexicographic order of Solve, without collecting them.
+// Both channels are closed once the enumeration ends; the error channel then
+// holds the reason it stopped early, if any, including the context being done
+func SolutionsChan(ctx context.Context, formula string, symbols []string) (<-chan map[string]bool, <-chan error) {
+	solutions := make(chan map[string]bool)
+	errs := make(chan error, 1)
+
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		close(solutions)
+		errs <- err
+		close(errs)
+		return solutions, errs
+	}
+
+	go func() {
+		defer close(errs)
+		defer close(solutions)
+
+		err := forEachCombination(ctx, formula, lexOrder(symbols), Options{}, func(values map[string]bool, res bool) bool {
+			if !res {
+				return true
+			}
+			select {
+			case solutions <- copyValues(values):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		if err == nil {
+			// The send may have been abandoned for the context
+			err = ctx.Err()
+		}
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return solutions, errs
+}
+
+// CountSolutions returns how many combinations of the symbols satisfy the
+// formula
+func CountSolutions(formula string, symbols []string) (int, error) {
+	return countSolutions(context.Background(), formula, symbols, Options{})
+}
+
+// countSolutions is CountSolutions with the options, giving up with the
+// context error once the context is done
+func countSolutions(ctx context.Context, formula string, symbols []string, opts Options) (int, error) {
+	symbols, _, err := checkRepeated(symbols, opts.Strict)
+	if err != nil {
+		return 0, err
+	}
+
+	// The order doesn't matter for counting, so the cheaper one is used
+	count := 0
+	err = forEachGray(ctx, formula, symbols, opts, func(_ uint64, res bool) bool {
+		if res {
+			count++
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SatisfactionRatio returns the fraction of the combinations of the symbols
+// that satisfy the formula, 1 for a tautology and 0 for a contradiction
+func SatisfactionRatio(formula string, symbols []string) (float64, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return 0, err
+	}
+
+	nCombinations, err := countCombinations(symbols)
+	if err != nil {
+		return 0, err
+	}
+	count, err := CountSolutions(formula, symbols)
+	if err != nil {
+		return 0, err
+	}
+	return float64(count) / float64(nCombinations), nil
+}
+
+// WeightedCount returns the sum over the combinations satisfying the formula
+// of the product of the weights of their values, where weights[v] holds the
+// weight of v being false and then true. The formula ranges over the symbols
+// of the weights, so with all of them at 0.5 this is the probability of the
+// formula being true
+func WeightedCount(formula string, weights map[string][2]float64) (float64, error) {
+	symbols := make([]string, 0, len(weights))
+	for symbol := range weights {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	total := 0.0
+	err := forEachGray(context.Background(), formula, symbols, Options{}, func(c uint64, res bool) bool {
+		if !res {
+			return true
+		}
+		product := 1.0
+		for j, symbol := range symbols {
+			product *= weights[symbol][(c>>j)&1]
+		}
+		total += product
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// Equivalent reports whether the two formulas agree on every combination of
+// the symbols. When they don't, the first combination they disagree on is
+// returned
+func Equivalent(f1, f2 string, symbols []string) (bool, map[string]bool, error) {
+	if err := checkFormulas(f1, f2); err != nil {
+		return false, nil, err
+	}
+	// Two formulas are equivalent when their equivalence is a tautology
+	return IsTautology(fmt.Sprintf("(%s) == (%s)", f1, f2), symbols)
+}
+
+// Entails reports whether every combination of the symbols satisfying the
+// premise also satisfies the conclusion. When it doesn't, the first such
+// combination falsifying the conclusion is returned
+func Entails(premise, conclusion string, symbols []string) (bool, map[string]bool, error) {
+	if err := checkFormulas(premise, conclusion); err != nil {
+		return false, nil, err
+	}
+	// The premise entails the conclusion when they can't disagree that way
+	return IsContradiction(fmt.Sprintf("(%s) && !(%s)", premise, conclusion), symbols)
+}
+
+// checkFormulas parses the formulas on their own before they are combined
+// into one, so that their errors point into the formula as written
+func checkFormulas(formulas ...string) error {
+	for _, formula := range formulas {
+		if _, err := parseFormula(formula); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EquivalenceClasses partitions the formulas into the groups that agree on
+// every combination of the symbols. The groups, and the formulas within each
+// of them, keep the order of their first appearance
+func EquivalenceClasses(formulas []string, symbols []string) ([][]string, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	var classes [][]string
+	class := make(map[string]int) // Index of the class of each truth table
+	for _, formula := range formulas {
+		results, err := outputs(formula, symbols)
+		if err != nil {
+			return nil, err
+		}
+
+		// Equivalent formulas are the ones with the same truth table
+		key := make([]byte, len(results))
+		for i, res := range results {
+			key[i] = '0'
+			if res {
+				key[i] = '1'
+			}
+		}
+
+		i, ok := class[string(key)]
+		if !ok {
+			i = len(classes)
+			class[string(key)] = i
+			classes = append(classes, nil)
+		}
+		classes[i] = append(classes[i], formula)
+	}
+	return classes, nil
+}
+
+// MinTrueSolution returns the satisfying combination of the symbols that sets
+// the fewest of them to true, the first one in the lexicographic order of
+// Solve among equals. The boolean is false if the formula is unsatisfiable
+func MinTrueSolution(formula string, symbols []string) (map[string]bool, bool, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var best map[string]bool
+	bestTrue := len(symbols) + 1
+	err = forEachCombination(context.Background(), formula, lexOrder(symbols), Options{}, func(values map[string]bool, res bool) bool {
+		if !res {
+			return true
+		}
+		nTrue := 0
+		for _, value := range values {
+			if value {
+				nTrue++
+			}
+		}
+		// Combinations come in ascending order, so only strictly better ones
+		// replace the current best
+		if nTrue < bestTrue {
+			best, bestTrue = copyValues(values), nTrue
+		}
+		// Nothing beats a combination with every symbol false
+		return nTrue > 0
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return best, best != nil, nil
+}
+
+// MaxSAT returns the combination of the symbols satisfying the most clauses,
+// the first one in the lexicographic order of Solve among equals, together
+// with how many clauses it satisfies. The clauses can be any formula
+func MaxSAT(clauses []string, symbols []string) (map[string]bool, int, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return nil, 0, err
+	}
+	symbols = lexOrder(symbols)
+
+	evals := make([]func(uint64) bool, len(clauses))
+	for i, clause := range clauses {
+		expr, err := parseFormula(clause)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := checkSymbols(expr, symbols); err != nil {
+			return nil, 0, err
+		}
+		if evals[i], err = compile(expr, symbols); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	nCombinations, err := countCombinations(symbols)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	best, bestCount := 0, -1
+	for i := 0; i < nCombinations && bestCount < len(clauses); i++ {
+		count := 0
+		for _, eval := range evals {
+			if eval(uint64(i)) {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = i, count
+		}
+	}
+	return combination(best, symbols), bestCount, nil
+}
+
+// TruthTable returns a row for every combination of the symbols in ascending
+// bit order, holding the value of each symbol followed by the result of the
+// formula. The first symbol is the most significant, so the rows follow the
+// lexicographic order of the columns, as the models of Solve do when the
+// symbols are sorted
+func TruthTable(formula string, symbols []string) ([][]bool, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return nil, err
+	}
+	return truthTable(context.Background(), formula, symbols, Options{})
+}
+
+// truthTable is TruthTable with the symbols as the columns, going through the
+// combinations in the lexicographic order of the columns. It gives up with
+// the context error once the context is done
+func truthTable(ctx context.Context, formula string, columns []string, opts Options) ([][]bool, error) {
+	var table [][]bool
+	err := forEachCombination(ctx, formula, bitOrder(columns, columns), opts, func(values map[string]bool, res bool) bool {
+		row := make([]bool, 0, len(columns)+1)
+		for _, symbol := range columns {
+			row = append(row, values[symbol])
+		}
+		table = append(table, append(row, res))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// RelevantVariables returns the symbols whose value changes the result of
+// the formula for at least one combination of the others
+func RelevantVariables(formula string, symbols []string) ([]string, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := outputs(formula, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	relevant := []string{}
+	for j, symbol := range symbols {
+		// Compare each combination with the one where the symbol is flipped
+		for i := range results {
+			if results[i] != results[i^(1<<(len(symbols)-1-j))] {
+				relevant = append(relevant, symbol)
+				break
+			}
+		}
+	}
+	return relevant, nil
+}
+
+// Backbone returns the symbols that take the same value in every satisfying
+// combination, together with that value. An unsatisfiable formula has no
+// model to force anything, so its backbone is empty
+func Backbone(formula string, symbols []string) (map[string]bool, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	solutions, err := FindAllSolutions(formula, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	backbone := make(map[string]bool)
+	if len(solutions) == 0 {
+		return backbone, nil
+	}
+	for _, symbol := range symbols {
+		fixed := true
+		for _, solution := range solutions[1:] {
+			if solution[symbol] != solutions[0][symbol] {
+				fixed = false
+				break
+			}
+		}
+		if fixed {
+			backbone[symbol] = solutions[0][symbol]
+		}
+	}
+	return backbone, nil
+}
+
+// evalPartial looks for a satisfying combination of the symbols that are not
+// already fixed, the lexicographically smallest one, returning it together
+// with the fixed values, or nil if there is none. Only the free symbols are
+// enumerated
+func evalPartial(expr ast.Expr, symbols []string, fixed map[string]bool) (map[string]bool, error) {
+	var free []string
+	for _, symbol := range symbols {
+		if _, ok := fixed[symbol]; !ok {
+			free = append(free, symbol)
+		}
+	}
+
+	// Search the formula left once the fixed values are folded in
+	model, err := search(context.Background(), formatExpr(fold(expr, fixed)), lexOrder(free), Options{})
+	if err != nil || model == nil {
+		return nil, err
+	}
+	for symbol, value := range fixed {
+		model[symbol] = value
+	}
+	return model, nil
+}
+
+// outputs returns the result of the formula for every combination of the
+// symbols, in the order of the rows of TruthTable
+func outputs(formula string, symbols []string) ([]bool, error) {
+	nCombinations, err := countCombinations(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]bool, nCombinations)
+	err = forEachGray(context.Background(), formula, symbols, Options{}, func(c uint64, res bool) bool {
+		results[reverseBits(c, len(symbols))] = res
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// forEachCombination evaluates the formula, written in the notation of the
+// options, on every combination of the symbols in ascending bit order, the
+// first symbol being the lowest bit, so the callers pass them through
+// bitOrder. It calls fn with each result until it returns false, or gives up
+// with the context error once the context is done. The values map is reused
+// between calls, so fn must copy it to keep it around
+func forEachCombination(ctx context.Context, formula string, symbols []string, opts Options, fn func(values map[string]bool, res bool) bool) error {
+	expr, err := opts.parseFormula(formula)
+	if err != nil {
+		return err
+	}
+	eval, err := compile(expr, symbols)
+	if err != nil {
+		return err
+	}
+
+	nCombinations, err := countCombinations(symbols)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]bool, len(symbols))
+	for i := 0; i < nCombinations; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		setCombination(values, i, symbols)
+		if !fn(values, eval(uint64(i))) {
+			break
+		}
+	}
+	return nil
+}
+
+// copyValues returns a copy of the values map
+func copyValues(values map[string]bool) map[string]bool {
+	c := make(map[string]bool, len(values))
+	for symbol, value := range values {
+		c[symbol] = value
+	}
+	return c
+}
@@ -0,0 +1,756 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindSolutionsLimit(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	solutions, err := FindSolutions("a || b || c", symbols, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]bool{
+		{"a": false, "b": false, "c": true},
+		{"a": false, "b": true, "c": false},
+	}
+	if !reflect.DeepEqual(solutions, want) {
+		t.Errorf("got %v, want %v", solutions, want)
+	}
+
+	// Without a limit, or with one past the number of models, all come back
+	for _, limit := range []int{0, -1, 7, 100} {
+		solutions, err := FindSolutions("a || b || c", symbols, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(solutions) != 7 {
+			t.Errorf("limit %d: got %d solutions, want 7", limit, len(solutions))
+		}
+	}
+}
+
+func TestFindSolutionsMatchSolve(t *testing.T) {
+	for _, formula := range []string{"a || b", "a ^ b ^ c", "!a && (b || c)", "c -> a"} {
+		symbols, err := ExtractSymbols(formula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The declared order must not matter
+		for i, j := 0, len(symbols)-1; i < j; i, j = i+1, j-1 {
+			symbols[i], symbols[j] = symbols[j], symbols[i]
+		}
+
+		result, err := Solve(formula, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		solutions, err := FindSolutions(formula, symbols, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(solutions) != 1 || !reflect.DeepEqual(solutions[0], result.Assignment) {
+			t.Errorf("%s: got first solution %v, want the model of Solve %v", formula, solutions, result.Assignment)
+		}
+	}
+}
+
+func TestFindAllSolutions(t *testing.T) {
+	solutions, err := FindAllSolutions("a || b", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]bool{
+		{"a": false, "b": true},
+		{"a": true, "b": false},
+		{"a": true, "b": true},
+	}
+	if !reflect.DeepEqual(solutions, want) {
+		t.Errorf("got %v, want %v", solutions, want)
+	}
+
+	solutions, err = FindAllSolutions("a && !a", []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(solutions) != 0 {
+		t.Errorf("got %v for a contradiction, want none", solutions)
+	}
+}
+
+func TestEvalPartial(t *testing.T) {
+	expr, err := parseFormula("a && b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbols := []string{"a", "b"}
+
+	model, err := evalPartial(expr, symbols, map[string]bool{"a": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": true, "b": true}; !reflect.DeepEqual(model, want) {
+		t.Errorf("a fixed to true: got %v, want %v", model, want)
+	}
+
+	model, err = evalPartial(expr, symbols, map[string]bool{"a": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model != nil {
+		t.Errorf("a fixed to false: got %v, want no completion", model)
+	}
+}
+
+func TestSolveWithAssumptions(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	result, err := SolveWithAssumptions("a && (b || c)", symbols, map[string]bool{"a": true, "b": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": true, "b": false, "c": true}; !result.Satisfiable || !reflect.DeepEqual(result.Assignment, want) {
+		t.Errorf("got %+v, want satisfiable by %v", result, want)
+	}
+
+	result, err = SolveWithAssumptions("a && (b || c)", symbols, map[string]bool{"a": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Satisfiable || result.Assignment != nil {
+		t.Errorf("a assumed false: got %+v, want unsatisfiable", result)
+	}
+
+	if _, err := SolveWithAssumptions("a", []string{"a"}, map[string]bool{"z": true}); err == nil {
+		t.Errorf("expected an error assuming a symbol that isn't declared")
+	}
+}
+
+func TestIsTautology(t *testing.T) {
+	for _, formula := range []string{"a || !a", "a -> a", "(a -> b) || (b -> a)", "true"} {
+		ok, counterexample, err := IsTautology(formula, []string{"a", "b"})
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		if !ok || counterexample != nil {
+			t.Errorf("%s: got %t with counterexample %v, want a tautology", formula, ok, counterexample)
+		}
+	}
+
+	for _, formula := range []string{"a || b", "a && !a", "a -> b"} {
+		ok, counterexample, err := IsTautology(formula, []string{"a", "b"})
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		if ok {
+			t.Errorf("%s: got a tautology", formula)
+			continue
+		}
+		// The counterexample must falsify the formula
+		res, err := Eval(formula, counterexample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res {
+			t.Errorf("%s: counterexample %v satisfies the formula", formula, counterexample)
+		}
+	}
+
+	if _, _, err := IsTautology("a || c", []string{"a"}); err == nil {
+		t.Errorf("expected an error for the undeclared c")
+	}
+}
+
+func TestIsContradiction(t *testing.T) {
+	for _, formula := range []string{"a && !a", "(a -> b) && a && !b", "false"} {
+		ok, model, err := IsContradiction(formula, []string{"a", "b"})
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		if !ok || model != nil {
+			t.Errorf("%s: got %t with model %v, want a contradiction", formula, ok, model)
+		}
+	}
+
+	ok, model, err := IsContradiction("a && !b", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": true, "b": false}; ok || !reflect.DeepEqual(model, want) {
+		t.Errorf("a && !b: got %t with model %v, want satisfiable by %v", ok, model, want)
+	}
+
+	// An undeclared symbol is an error, not a panic
+	if _, _, err := IsContradiction("a && z", []string{"a"}); err == nil {
+		t.Errorf("expected an error for the undeclared z")
+	}
+}
+
+func TestFindAllSolutionsStable(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	first, err := FindAllSolutions("a ^ b || c && !d", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		again, err := FindAllSolutions("a ^ b || c && !d", symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(again, first) {
+			t.Fatalf("run %d: got %v, want %v", i, again, first)
+		}
+	}
+}
+
+func TestCountSolutions(t *testing.T) {
+	tests := []struct {
+		formula string
+		symbols []string
+		want    int
+	}{
+		{"a || !a", []string{"a"}, 2},
+		{"a || !a", []string{"a", "b", "c"}, 8},
+		{"a && !a", []string{"a", "b", "c"}, 0},
+		{"a && b || c", []string{"a", "b", "c"}, 5},
+		{"a ^ b ^ c", []string{"a", "b", "c"}, 4},
+		{"true", nil, 1},
+		{"false", nil, 0},
+	}
+	for _, test := range tests {
+		got, err := CountSolutions(test.formula, test.symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if got != test.want {
+			t.Errorf("%s over %v: got %d solutions, want %d", test.formula, test.symbols, got, test.want)
+		}
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	symbols := []string{"a", "b"}
+	ok, _, err := Equivalent("a && b", "b && a", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("a && b and b && a: expected equivalent")
+	}
+	ok, _, err = Equivalent("!(a && b)", "!a || !b", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("De Morgan: expected equivalent")
+	}
+
+	ok, witness, err := Equivalent("a -> b", "b -> a", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("a -> b and b -> a: expected not equivalent")
+	}
+	// The formulas disagree on the witness
+	x, _ := Eval("a -> b", witness)
+	y, _ := Eval("b -> a", witness)
+	if x == y {
+		t.Errorf("witness %v: both formulas give %t", witness, x)
+	}
+}
+
+func TestTruthTable(t *testing.T) {
+	table, err := TruthTable("a && b", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// In lexicographic order, a being the highest bit
+	want := [][]bool{
+		{false, false, false},
+		{false, true, false},
+		{true, false, false},
+		{true, true, true},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("got %v, want %v", table, want)
+	}
+
+	table, err = TruthTable("true", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]bool{{true}}; !reflect.DeepEqual(table, want) {
+		t.Errorf("constant: got %v, want %v", table, want)
+	}
+}
+
+func TestMinTrueSolution(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    map[string]bool
+	}{
+		// Among equals, the first in the lexicographic order of Solve
+		{"a || b", map[string]bool{"a": false, "b": true}},
+		{"a && b", map[string]bool{"a": true, "b": true}},
+		{"!a && !b", map[string]bool{"a": false, "b": false}},
+		{"(a || b) && (b || !a)", map[string]bool{"a": false, "b": true}},
+	}
+	for _, test := range tests {
+		got, ok, err := MinTrueSolution(test.formula, []string{"a", "b"})
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if !ok || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, %t, want %v", test.formula, got, ok, test.want)
+		}
+	}
+
+	got, ok, err := MinTrueSolution("a && !a", []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok || got != nil {
+		t.Errorf("a && !a: got %v, %t, want no solution", got, ok)
+	}
+}
+
+func TestRelevantVariables(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    []string
+	}{
+		{"a && b", []string{"a", "b"}},
+		{"a || !a || c", []string{}},
+		{"(a && c) || (!a && c)", []string{"c"}},
+		{"a != b != c", []string{"a", "b", "c"}},
+	}
+	for _, test := range tests {
+		got, err := RelevantVariables(test.formula, []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.formula, got, test.want)
+		}
+	}
+}
+
+func TestBackbone(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    map[string]bool
+	}{
+		{"a && (b || c)", map[string]bool{"a": true}},
+		{"!a && b && (b || c)", map[string]bool{"a": false, "b": true}},
+		{"a || b || c", map[string]bool{}},
+		{"a && !a", map[string]bool{}},
+	}
+	for _, test := range tests {
+		got, err := Backbone(test.formula, []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.formula, got, test.want)
+		}
+	}
+}
+
+func TestSolutionsChan(t *testing.T) {
+	solutions, errs := SolutionsChan(context.Background(), "a || b || c", []string{"a", "b", "c"})
+	var got []map[string]bool
+	for solution := range solutions {
+		got = append(got, solution)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	// In the lexicographic order of Solve, a being the highest bit
+	want := []map[string]bool{
+		{"a": false, "b": false, "c": true},
+		{"a": false, "b": true, "c": false},
+		{"a": false, "b": true, "c": true},
+		{"a": true, "b": false, "c": false},
+		{"a": true, "b": false, "c": true},
+		{"a": true, "b": true, "c": false},
+		{"a": true, "b": true, "c": true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSolutionsChanCancel(t *testing.T) {
+	// Far more solutions than anyone could collect
+	symbols := manySymbols(40)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// s8 and s9 come last in alphabetical order, so they are the lowest bits
+	solutions, errs := SolutionsChan(ctx, "s8 || s9", symbols)
+
+	const n = 5
+	for i := 0; i < n; i++ {
+		solution, ok := <-solutions
+		if !ok {
+			t.Fatalf("got %d solutions, want at least %d", i, n)
+		}
+		if !solution["s8"] && !solution["s9"] {
+			t.Errorf("got %v, which doesn't satisfy the formula", solution)
+		}
+	}
+	cancel()
+
+	// Both channels are closed soon after
+	for range solutions {
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSolutionsChanErrors(t *testing.T) {
+	solutions, errs := SolutionsChan(context.Background(), "a && c", []string{"a"})
+	for solution := range solutions {
+		t.Errorf("got solution %v of an invalid formula", solution)
+	}
+	if err := <-errs; err == nil {
+		t.Errorf("expected an error for the undeclared c")
+	}
+}
+
+func TestSatisfactionRatio(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    float64
+	}{
+		{"a || !a", 1},
+		{"a && !a", 0},
+		{"a || b", 0.75},
+		{"a && b", 0.25},
+		{"a != b", 0.5},
+	}
+	for _, test := range tests {
+		got, err := SatisfactionRatio(test.formula, []string{"a", "b"})
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.formula, got, test.want)
+		}
+	}
+
+	// The symbols the formula doesn't use don't change the ratio
+	got, err := SatisfactionRatio("a || b", []string{"a", "b", "c", "d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 0.75 {
+		t.Errorf("a || b over four symbols: got %v, want 0.75", got)
+	}
+}
+
+func TestEquivalenceClasses(t *testing.T) {
+	classes, err := EquivalenceClasses([]string{"a&&b", "b&&a", "a||b"}, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"a&&b", "b&&a"}, {"a||b"}}; !reflect.DeepEqual(classes, want) {
+		t.Errorf("got %q, want %q", classes, want)
+	}
+
+	formulas := []string{"a -> b", "a", "!a || b", "!!a", "!(a && !b)", "a && (a || b)"}
+	classes, err = EquivalenceClasses(formulas, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"a -> b", "!a || b", "!(a && !b)"}, {"a", "!!a", "a && (a || b)"}}; !reflect.DeepEqual(classes, want) {
+		t.Errorf("got %q, want %q", classes, want)
+	}
+
+	if _, err := EquivalenceClasses([]string{"a", "a && c"}, []string{"a"}); err == nil {
+		t.Errorf("expected an error for the undeclared c")
+	}
+}
+
+func TestEntails(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	tests := []struct {
+		premise, conclusion string
+		entails             bool
+	}{
+		{"a && b", "a", true},
+		{"a", "a && b", false},
+		{"a && (a -> b)", "b", true},
+		{"a || b", "a", false},
+		{"a && !a", "c", true},
+		{"c", "a || !a", true},
+		{"(a -> b) && (b -> c)", "a -> c", true},
+		{"a -> b", "b -> a", false},
+	}
+	for _, test := range tests {
+		entails, counter, err := Entails(test.premise, test.conclusion, symbols)
+		if err != nil {
+			t.Fatalf("%s entails %s: %v", test.premise, test.conclusion, err)
+		}
+		if entails != test.entails {
+			t.Errorf("%s entails %s: got %t, want %t", test.premise, test.conclusion, entails, test.entails)
+			continue
+		}
+		if entails {
+			if counter != nil {
+				t.Errorf("%s entails %s: got counterexample %v", test.premise, test.conclusion, counter)
+			}
+			continue
+		}
+		// The counterexample satisfies the premise but not the conclusion
+		premise, _ := Eval(test.premise, counter)
+		conclusion, _ := Eval(test.conclusion, counter)
+		if !premise || conclusion {
+			t.Errorf("%s entails %s: got counterexample %v", test.premise, test.conclusion, counter)
+		}
+	}
+
+	_, counter, err := Entails("a", "a && b", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": true, "b": false}; !reflect.DeepEqual(counter, want) {
+		t.Errorf("a entails a && b: got counterexample %v, want %v", counter, want)
+	}
+}
+
+func TestEnumerationOrder(t *testing.T) {
+	// Every enumeration follows the lexicographic order of Solve, whatever
+	// the order the symbols are given in
+	formula := "a || b || c"
+	symbols := []string{"c", "a", "b"}
+	result, err := Solve(formula, symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := result.Assignment
+
+	all, err := FindAllSolutions(formula, symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, model, err := IsContradiction(formula, symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, counter, err := IsTautology("!("+formula+")", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, entailed, err := Entails(formula, "false", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, differing, err := Equivalent(formula, "false", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solutions, errs := SolutionsChan(context.Background(), formula, symbols)
+	streamed := <-solutions
+	for range solutions {
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	maxSAT, _, err := MaxSAT([]string{formula}, symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firsts := map[string]map[string]bool{
+		"FindAllSolutions": all[0],
+		"IsContradiction":  model,
+		"IsTautology":      counter,
+		"Entails":          entailed,
+		"Equivalent":       differing,
+		"SolutionsChan":    streamed,
+		"MaxSAT":           maxSAT,
+	}
+	for name, got := range firsts {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v first, Solve gives %v", name, got, want)
+		}
+	}
+}
+
+func TestIsTautologyShortCircuits(t *testing.T) {
+	// Refuted by the very first combination, with every symbol false, out
+	// of 2^24
+	symbols := manySymbols(24)
+	valid, counter, stats, err := isTautology(strings.Join(symbols, " || "), symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid || counter == nil {
+		t.Fatalf("got valid %t, want a counterexample", valid)
+	}
+	// The workers may each have taken one more before stopping
+	if !stats.ShortCircuited || stats.Evaluated == 0 || stats.Evaluated > 100 {
+		t.Errorf("got %+v, want a search stopped after a few combinations", stats)
+	}
+}
+
+func TestIsContradictionShortCircuits(t *testing.T) {
+	// Satisfied by the second combination, s9 being the last symbol in
+	// alphabetical order and so the lowest bit
+	symbols := manySymbols(24)
+	contradiction, model, stats, err := isContradiction("s9", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contradiction || model == nil {
+		t.Fatalf("got contradiction %t, want a model", contradiction)
+	}
+	if !stats.ShortCircuited || stats.Evaluated == 0 || stats.Evaluated > 100 {
+		t.Errorf("got %+v, want a search stopped after a few combinations", stats)
+	}
+}
+
+func TestMaxSAT(t *testing.T) {
+	tests := []struct {
+		clauses []string
+		want    map[string]bool
+		count   int
+	}{
+		// Any two of the three, but never all of them
+		{[]string{"a", "!a || b", "!b"}, map[string]bool{"a": false, "b": false}, 2},
+		{[]string{"a && b", "!a", "!b"}, map[string]bool{"a": false, "b": false}, 2},
+		{[]string{"a", "b", "!a || !b", "a"}, map[string]bool{"a": true, "b": false}, 3},
+		// Jointly satisfiable
+		{[]string{"a || b", "!a"}, map[string]bool{"a": false, "b": true}, 2},
+		{nil, map[string]bool{"a": false, "b": false}, 0},
+	}
+	for _, test := range tests {
+		model, count, err := MaxSAT(test.clauses, []string{"a", "b"})
+		if err != nil {
+			t.Fatalf("%q: %v", test.clauses, err)
+		}
+		if count != test.count || !reflect.DeepEqual(model, test.want) {
+			t.Errorf("%q: got %v satisfying %d, want %v satisfying %d", test.clauses, model, count, test.want, test.count)
+		}
+		// The count is the one of the model
+		satisfied := 0
+		for _, clause := range test.clauses {
+			if res, _ := Eval(clause, model); res {
+				satisfied++
+			}
+		}
+		if satisfied != count {
+			t.Errorf("%q: %v satisfies %d clauses, not %d", test.clauses, model, satisfied, count)
+		}
+	}
+
+	if _, _, err := MaxSAT([]string{"a", "c"}, []string{"a"}); err == nil {
+		t.Errorf("expected an error for the undeclared c")
+	}
+}
+
+func TestWeightedCount(t *testing.T) {
+	ab := map[string][2]float64{"a": {0.3, 0.7}, "b": {0.6, 0.4}}
+	half := map[string][2]float64{"a": {0.5, 0.5}, "b": {0.5, 0.5}, "c": {0.5, 0.5}}
+	unit := map[string][2]float64{"a": {1, 1}, "b": {1, 1}, "c": {1, 1}}
+	tests := []struct {
+		formula string
+		weights map[string][2]float64
+		want    float64
+	}{
+		{"a || b", ab, 1 - 0.3*0.6},
+		{"a && !b", ab, 0.7 * 0.6},
+		{"a == b", ab, 0.3*0.6 + 0.7*0.4},
+		{"a && !a", ab, 0},
+		{"a || !a", ab, 1},
+		// With all the weights at 0.5, the probability of being true
+		{"a ^ b", half, 0.5},
+		{"a && b && c", half, 0.125},
+		{"a || b || c", half, 0.875},
+		// With all of them at 1, the number of models
+		{"a || b", unit, 6},
+		{"exactly(2, a, b, c)", unit, 3},
+	}
+	for _, test := range tests {
+		got, err := WeightedCount(test.formula, test.weights)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: got %v, want %v", test.formula, got, test.want)
+		}
+	}
+
+	if _, err := WeightedCount("a && c", ab); err == nil {
+		t.Errorf("expected an error for c, which has no weights")
+	}
+}
+
+func TestSolveWithAssumptionsUnsatisfiable(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	tests := []struct {
+		formula     string
+		assumptions map[string]bool
+	}{
+		{"a || b", map[string]bool{"a": false, "b": false}},
+		{"a -> b", map[string]bool{"a": true, "b": false}},
+		{"(a || c) && (b || !c)", map[string]bool{"a": false, "b": false}},
+		{"exactly(1, a, b, c)", map[string]bool{"a": true, "c": true}},
+	}
+	for _, test := range tests {
+		// Satisfiable on its own, but not under the assumptions
+		if result, err := Solve(test.formula, symbols); err != nil || !result.Satisfiable {
+			t.Fatalf("%s: got %+v, %v, want satisfiable", test.formula, result, err)
+		}
+		result, err := SolveWithAssumptions(test.formula, symbols, test.assumptions)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if result.Satisfiable || result.Assignment != nil {
+			t.Errorf("%s assuming %v: got %+v, want unsatisfiable", test.formula, test.assumptions, result)
+		}
+	}
+}
+
+// TestSolveWithAssumptionsRandom compares the assumptions with conjoining
+// their literals to the formula
+func TestSolveWithAssumptionsRandom(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	assumptions := map[string]bool{"b": true, "d": false}
+	for seed := int64(1); seed <= 100; seed++ {
+		formula := RandomFormula(symbols, 5, seed)
+		want, err := Solve("("+formula+") && b && !d", symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		got, err := SolveWithAssumptions(formula, symbols, assumptions)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		if got.Satisfiable != want.Satisfiable || !reflect.DeepEqual(got.Assignment, want.Assignment) {
+			t.Errorf("%s: got %v, want %v", formula, got.Assignment, want.Assignment)
+		}
+	}
+}
+
+func TestSolveWithAssumptionsFree(t *testing.T) {
+	// Far too many symbols to enumerate, but only s0 is free
+	symbols := manySymbols(60)
+	assumptions := make(map[string]bool)
+	for _, symbol := range symbols[1:] {
+		assumptions[symbol] = true
+	}
+	result, err := SolveWithAssumptions("!s0 && s59", symbols, assumptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Satisfiable || result.Assignment["s0"] || len(result.Assignment) != 60 {
+		t.Errorf("got %+v, want s0 false and the assumptions", result)
+	}
+}
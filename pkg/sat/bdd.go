@@ -0,0 +1,328 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// BDD is a reduced ordered binary decision diagram of a formula. Its nodes
+// are shared through a unique table, so that every function over the order
+// has a single diagram
+type BDD struct {
+	nodes  []bddNode
+	unique map[bddNode]int
+	apply  map[bddOp]int
+	order  []string
+	expr   ast.Expr // Kept to rebuild the diagram over another order
+	root   int
+
+	// The diagrams of the expressions already built, shared by the nodes of
+	// the expanded cardinality constraints
+	built map[ast.Expr]int
+}
+
+// bddNode tests the symbol at level, going to low if it is false and to high
+// if it is true. The terminals false and true are the nodes 0 and 1, at the
+// level past the last symbol
+type bddNode struct {
+	level, low, high int
+}
+
+// bddOp is the key of the memoized applications of an operator
+type bddOp struct {
+	op   token.Token
+	u, v int
+}
+
+// BuildBDD builds the diagram of the formula, testing the symbols in the
+// given order
+func BuildBDD(formula string, order []string) (*BDD, error) {
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return nil, err
+	}
+	return buildBDD(expr, order)
+}
+
+// buildBDD builds the diagram of an already parsed formula
+func buildBDD(expr ast.Expr, order []string) (*BDD, error) {
+	// The number of solutions, up to 2^n, must fit in an int
+	if len(order) > maxSymbols {
+		return nil, fmt.Errorf("too many symbols for a BDD (max %d): %d", maxSymbols, len(order))
+	}
+	if err := checkConstants(order); err != nil {
+		return nil, err
+	}
+
+	levels := make(map[string]int, len(order))
+	for i, symbol := range order {
+		if _, ok := levels[symbol]; ok {
+			return nil, fmt.Errorf("symbol '%s' repeated in the order", symbol)
+		}
+		levels[symbol] = i
+	}
+
+	n := len(order)
+	b := &BDD{
+		nodes:  []bddNode{{level: n}, {level: n}},
+		unique: make(map[bddNode]int),
+		apply:  make(map[bddOp]int),
+		order:  order,
+		expr:   expr,
+		built:  make(map[ast.Expr]int),
+	}
+
+	root, err := b.build(expr, levels)
+	if err != nil {
+		return nil, err
+	}
+	b.root = root
+	// Their nodes are done with once the diagram is built
+	b.built = nil
+	return b, nil
+}
+
+// build adds the nodes of the expression and returns its root, building the
+// expressions shared by several nodes once
+func (b *BDD) build(node ast.Expr, levels map[string]int) (int, error) {
+	if root, ok := b.built[node]; ok {
+		return root, nil
+	}
+	root, err := b.buildNode(node, levels)
+	if err != nil {
+		return 0, err
+	}
+	b.built[node] = root
+	return root, nil
+}
+
+// buildNode is build for an expression not built yet
+func (b *BDD) buildNode(node ast.Expr, levels map[string]int) (int, error) {
+	switch expr := node.(type) {
+	case *ast.Ident:
+		if level, ok := levels[expr.Name]; ok {
+			return b.mk(level, 0, 1), nil
+		}
+		switch expr.Name {
+		case "true":
+			return 1, nil
+		case "false":
+			return 0, nil
+		}
+		return 0, fmt.Errorf("identifier '%s' not found in input values", expr.Name)
+
+	case *ast.UnaryExpr:
+		if expr.Op != token.NOT {
+			return 0, fmt.Errorf("unsupported unary operator: %s", expr.Op)
+		}
+		x, err := b.build(expr.X, levels)
+		if err != nil {
+			return 0, err
+		}
+		return b.applyOp(token.XOR, x, 1), nil
+
+	case *ast.BinaryExpr:
+		switch expr.Op {
+		case token.LAND, token.LOR, token.XOR, token.NEQ, token.EQL:
+		default:
+			return 0, fmt.Errorf("unsupported binary operator: %s", expr.Op)
+		}
+		x, err := b.build(expr.X, levels)
+		if err != nil {
+			return 0, err
+		}
+		y, err := b.build(expr.Y, levels)
+		if err != nil {
+			return 0, err
+		}
+		return b.applyOp(expr.Op, x, y), nil
+
+	case *ast.CallExpr:
+		lowered, err := lowerCall(expr, false)
+		if err != nil {
+			return 0, err
+		}
+		return b.build(lowered, levels)
+
+	case *ast.ParenExpr:
+		return b.build(expr.X, levels)
+
+	default:
+		return 0, fmt.Errorf("unsupported expression type: %T", node)
+	}
+}
+
+// mk returns the node testing the level, reusing an existing one if there is
+// already one, and skipping the test if both branches are the same
+func (b *BDD) mk(level, low, high int) int {
+	if low == high {
+		return low
+	}
+	n := bddNode{level: level, low: low, high: high}
+	if i, ok := b.unique[n]; ok {
+		return i
+	}
+	b.nodes = append(b.nodes, n)
+	b.unique[n] = len(b.nodes) - 1
+	return len(b.nodes) - 1
+}
+
+// applyOp returns the diagram of the operator applied to the two diagrams
+func (b *BDD) applyOp(op token.Token, u, v int) int {
+	if u <= 1 && v <= 1 {
+		x, y := u == 1, v == 1
+		var res bool
+		switch op {
+		case token.LAND:
+			res = x && y
+		case token.LOR:
+			res = x || y
+		case token.XOR, token.NEQ:
+			res = x != y
+		case token.EQL:
+			res = x == y
+		}
+		if res {
+			return 1
+		}
+		return 0
+	}
+
+	key := bddOp{op: op, u: u, v: v}
+	if i, ok := b.apply[key]; ok {
+		return i
+	}
+
+	// Split both diagrams on the earliest symbol either of them tests
+	level := b.nodes[u].level
+	if l := b.nodes[v].level; l < level {
+		level = l
+	}
+	uLow, uHigh := b.cofactors(u, level)
+	vLow, vHigh := b.cofactors(v, level)
+	i := b.mk(level, b.applyOp(op, uLow, vLow), b.applyOp(op, uHigh, vHigh))
+
+	b.apply[key] = i
+	return i
+}
+
+// cofactors returns the branches of the node for the symbol at level, the
+// node itself for both if it doesn't test it
+func (b *BDD) cofactors(u, level int) (int, int) {
+	if b.nodes[u].level != level {
+		return u, u
+	}
+	return b.nodes[u].low, b.nodes[u].high
+}
+
+// Order returns the order the symbols are tested in
+func (b *BDD) Order() []string {
+	return b.order
+}
+
+// Size returns the number of nodes of the diagram, terminals included
+func (b *BDD) Size() int {
+	seen := make(map[int]bool)
+	var visit func(u int)
+	visit = func(u int) {
+		if seen[u] {
+			return
+		}
+		seen[u] = true
+		if u > 1 {
+			visit(b.nodes[u].low)
+			visit(b.nodes[u].high)
+		}
+	}
+	visit(b.root)
+	return len(seen)
+}
+
+// SatCount returns how many combinations of the symbols of the order satisfy
+// the formula, in time linear in the size of the diagram
+func (b *BDD) SatCount() int {
+	memo := make(map[int]int)
+	// count returns the solutions over the symbols from the level of u on
+	var count func(u int) int
+	count = func(u int) int {
+		if u <= 1 {
+			return u
+		}
+		if c, ok := memo[u]; ok {
+			return c
+		}
+		n := b.nodes[u]
+		// The symbols skipped by a branch are free
+		low := count(n.low) << (b.nodes[n.low].level - n.level - 1)
+		high := count(n.high) << (b.nodes[n.high].level - n.level - 1)
+		memo[u] = low + high
+		return low + high
+	}
+	return count(b.root) << b.nodes[b.root].level
+}
+
+// Equivalent reports whether the two diagrams represent the same function.
+// Over the same order this is a walk of the diagrams, which are then
+// isomorphic, otherwise the other diagram is built again over this order
+// followed by the symbols it is missing
+func (b *BDD) Equivalent(other *BDD) bool {
+	if !sameOrder(b.order, other.order) {
+		order := append([]string(nil), b.order...)
+		for _, symbol := range other.order {
+			if !contains(order, symbol) {
+				order = append(order, symbol)
+			}
+		}
+		// Both formulas were already built, so they can be built again
+		x, errX := buildBDD(b.expr, order)
+		y, errY := buildBDD(other.expr, order)
+		if errX != nil || errY != nil {
+			return false
+		}
+		return x.Equivalent(y)
+	}
+
+	type pair struct{ u, v int }
+	seen := make(map[pair]bool)
+	var isomorphic func(u, v int) bool
+	isomorphic = func(u, v int) bool {
+		if u <= 1 || v <= 1 {
+			return u == v
+		}
+		if seen[pair{u, v}] {
+			return true
+		}
+		x, y := b.nodes[u], other.nodes[v]
+		if x.level != y.level || !isomorphic(x.low, y.low) || !isomorphic(x.high, y.high) {
+			return false
+		}
+		seen[pair{u, v}] = true
+		return true
+	}
+	return isomorphic(b.root, other.root)
+}
+
+// sameOrder reports whether the two orders test the same symbols in the same
+// sequence
+func sameOrder(x, y []string) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// contains reports whether the symbol is one of the symbols
+func contains(symbols []string, symbol string) bool {
+	for _, s := range symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
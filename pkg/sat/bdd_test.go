@@ -0,0 +1,106 @@
+package sat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBDDSatCount(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	for seed := int64(1); seed <= 200; seed++ {
+		formula := RandomFormula(symbols, 5, seed)
+		b, err := BuildBDD(formula, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		want, err := CountSolutions(formula, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		if got := b.SatCount(); got != want {
+			t.Errorf("%s: got %d solutions, want %d", formula, got, want)
+		}
+	}
+}
+
+// TestBDDSatCountLarge counts the solutions of a parity over more symbols
+// than could be enumerated, which the diagram holds in two nodes per symbol
+func TestBDDSatCountLarge(t *testing.T) {
+	symbols := manySymbols(40)
+	b, err := BuildBDD(strings.Join(symbols, " ^ "), symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.SatCount(), 1<<39; got != want {
+		t.Errorf("got %d solutions, want %d", got, want)
+	}
+	if got, want := b.Size(), 2*40+1; got != want {
+		t.Errorf("got %d nodes, want %d", got, want)
+	}
+}
+
+func TestBDDEquivalent(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	tests := []struct {
+		x, y       string
+		equivalent bool
+	}{
+		{"a && b", "b && a", true},
+		{"a -> b", "!a || b", true},
+		{"!(a && b) || c", "!a || !b || c", true},
+		{"(a || b) && (a || c)", "a || (b && c)", true},
+		{"a ^ b ^ c", "!(a == b) != c", true},
+		{"a && b", "a || b", false},
+		{"a", "a && (b || !b) && c", false},
+	}
+	for _, test := range tests {
+		x, err := BuildBDD(test.x, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := BuildBDD(test.y, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := x.Equivalent(y); got != test.equivalent {
+			t.Errorf("%s and %s: got equivalent %t, want %t", test.x, test.y, got, test.equivalent)
+		}
+		// Over the same order, equivalent formulas have the same diagram
+		if test.equivalent && x.Size() != y.Size() {
+			t.Errorf("%s and %s: got %d and %d nodes", test.x, test.y, x.Size(), y.Size())
+		}
+	}
+}
+
+func TestBDDEquivalentOrders(t *testing.T) {
+	x, err := BuildBDD("a && (b || c)", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := BuildBDD("(c || b) && a", []string{"c", "b", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !x.Equivalent(y) || !y.Equivalent(x) {
+		t.Errorf("expected the diagrams over different orders to be equivalent")
+	}
+	z, err := BuildBDD("a && b", []string{"b", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Equivalent(z) {
+		t.Errorf("a && (b || c) and a && b: expected them to differ")
+	}
+}
+
+func TestBDDErrors(t *testing.T) {
+	if _, err := BuildBDD("a && b", []string{"a", "b", "a"}); err == nil {
+		t.Errorf("expected an error for the repeated symbol")
+	}
+	if _, err := BuildBDD("a && b", []string{"a"}); err == nil {
+		t.Errorf("expected an error for the symbol missing from the order")
+	}
+	if _, err := BuildBDD("a &&", []string{"a"}); err == nil {
+		t.Errorf("expected an error for a formula that doesn't parse")
+	}
+}
@@ -0,0 +1,225 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+// builtin is a boolean function formulas can call by name
+type builtin struct {
+	minArgs int                    // Fewest arguments accepted
+	maxArgs int                    // Most arguments accepted, -1 if unbounded
+	eval    func(args []bool) bool // Result on the evaluated arguments
+
+	// The cardinality constraints take an integer count k before the
+	// formulas, and have count instead of eval: their result given how
+	// many of the formulas are true. Where the structure is needed, expand
+	// rewrites them into the usual operators
+	count  func(k, nTrue int) bool
+	expand func(k int, operands []ast.Expr) ast.Expr
+}
+
+// builtins are the functions available to the formulas, by name. Adding an
+// entry here is enough for a new function to be evaluated, compiled and
+// converted like the operators
+var builtins = map[string]builtin{
+	"atleast": {
+		minArgs: 1, maxArgs: -1,
+		count:  func(k, nTrue int) bool { return nTrue >= k },
+		expand: atLeast,
+	},
+	"atmost": {
+		minArgs: 1, maxArgs: -1,
+		count: func(k, nTrue int) bool { return nTrue <= k },
+		expand: func(k int, operands []ast.Expr) ast.Expr {
+			// At most k are true when it's not the case that k+1 are
+			return negate(atLeast(k+1, operands))
+		},
+	},
+	"exactly": {
+		minArgs: 1, maxArgs: -1,
+		count: func(k, nTrue int) bool { return nTrue == k },
+		expand: func(k int, operands []ast.Expr) ast.Expr {
+			return conjoin(atLeast(k, operands), negate(atLeast(k+1, operands)))
+		},
+	},
+	"ite": {minArgs: 3, maxArgs: 3, eval: func(args []bool) bool {
+		// If the first argument then the second else the third
+		if args[0] {
+			return args[1]
+		}
+		return args[2]
+	}},
+	"majority": {minArgs: 1, maxArgs: -1, eval: func(args []bool) bool {
+		// Strictly more than half of the arguments, so ties are false
+		nTrue := 0
+		for _, arg := range args {
+			if arg {
+				nTrue++
+			}
+		}
+		return 2*nTrue > len(args)
+	}},
+	"nand": {minArgs: 2, maxArgs: -1, eval: func(args []bool) bool { return !all(args) }},
+	"nor":  {minArgs: 2, maxArgs: -1, eval: func(args []bool) bool { return !anyTrue(args) }},
+	"xnor": {minArgs: 2, maxArgs: -1, eval: func(args []bool) bool {
+		// True when the arguments are all equal, which for two of them is
+		// their equivalence
+		return all(args) || !anyTrue(args)
+	}},
+	"xor": {minArgs: 2, maxArgs: -1, eval: func(args []bool) bool {
+		// True when an odd number of arguments is
+		odd := false
+		for _, arg := range args {
+			odd = odd != arg
+		}
+		return odd
+	}},
+}
+
+// IsBuiltin reports whether the formulas can call a function of that name
+func IsBuiltin(name string) bool {
+	_, ok := builtins[name]
+	return ok
+}
+
+// lookupBuiltin returns the function the call refers to, checking that it
+// exists and that the number of arguments is right
+func lookupBuiltin(call *ast.CallExpr) (builtin, error) {
+	name, ok := call.Fun.(*ast.Ident)
+	if !ok || name == nil {
+		return builtin{}, fmt.Errorf("unsupported function: %T", call.Fun)
+	}
+	f, ok := builtins[name.Name]
+	if !ok {
+		return builtin{}, fmt.Errorf("unknown function '%s'", name.Name)
+	}
+
+	n := len(call.Args)
+	switch {
+	case f.minArgs == f.maxArgs && n != f.minArgs:
+		return builtin{}, fmt.Errorf("%s: expected %d arguments, found %d", name.Name, f.minArgs, n)
+	case n < f.minArgs:
+		return builtin{}, fmt.Errorf("%s: expected at least %d arguments, found %d", name.Name, f.minArgs, n)
+	case f.maxArgs >= 0 && n > f.maxArgs:
+		return builtin{}, fmt.Errorf("%s: expected at most %d arguments, found %d", name.Name, f.maxArgs, n)
+	}
+	return f, nil
+}
+
+// maxLoweredArgs is the most arguments of a builtin call, other than the
+// cardinality constraints, that lowerCall rewrites into the operators. Its
+// rewriting has a clause for each of the 2^n combinations of the arguments
+const maxLoweredArgs = 12
+
+// boundCall is a call to a builtin with the count of a cardinality
+// constraint read, so that it only applies to the formulas left
+type boundCall struct {
+	f        builtin
+	k        int
+	operands []ast.Expr
+}
+
+// resolveCall looks up the builtin the call refers to, checking its
+// arguments, and splits off the count of a cardinality constraint
+func resolveCall(call *ast.CallExpr) (boundCall, error) {
+	f, err := lookupBuiltin(call)
+	if err != nil {
+		return boundCall{}, err
+	}
+	if f.count == nil {
+		return boundCall{f: f, operands: call.Args}, nil
+	}
+	k, operands, err := cardinality(call.Fun.(*ast.Ident).Name, call.Args)
+	if err != nil {
+		return boundCall{}, err
+	}
+	return boundCall{f: f, k: k, operands: operands}, nil
+}
+
+// apply returns the result of the call on the values of its operands
+func (c boundCall) apply(values []bool) bool {
+	if c.f.count != nil {
+		nTrue := 0
+		for _, value := range values {
+			if value {
+				nTrue++
+			}
+		}
+		return c.f.count(c.k, nTrue)
+	}
+	return c.f.eval(values)
+}
+
+// lowerCall rewrites the call, or its negation if negated, into the usual
+// operators. The cardinality constraints are expanded with the shared nodes
+// of atLeast, so the walks of the result must be memoized on the nodes. The
+// other builtins become the conjunction of a clause ruling out each
+// combination of the arguments for which the result is false. That grows
+// exponentially with the number of arguments, so it is only meant for the
+// conversions that need the structure of the formula
+func lowerCall(call *ast.CallExpr, negated bool) (ast.Expr, error) {
+	c, err := resolveCall(call)
+	if err != nil {
+		return nil, err
+	}
+	if c.f.expand != nil {
+		lowered := c.f.expand(c.k, c.operands)
+		if negated {
+			return negate(lowered), nil
+		}
+		return lowered, nil
+	}
+	if n := len(c.operands); n > maxLoweredArgs {
+		name := call.Fun.(*ast.Ident).Name
+		return nil, fmt.Errorf("%s: too many arguments to rewrite into the operators (max %d): %d", name, maxLoweredArgs, n)
+	}
+
+	var result ast.Expr = constant(true)
+	values := make([]bool, len(c.operands))
+	for i := 0; i < 1<<len(c.operands); i++ {
+		for j := range values {
+			values[j] = (i>>j)&1 == 1
+		}
+		if c.apply(values) != negated {
+			continue
+		}
+
+		// At least one argument must differ from this combination
+		var clause ast.Expr = constant(false)
+		for j, arg := range c.operands {
+			if values[j] {
+				clause = disjoin(clause, negate(arg))
+			} else {
+				clause = disjoin(clause, arg)
+			}
+		}
+		result = conjoin(result, clause)
+	}
+	return result, nil
+}
+
+// all reports whether every value is true
+func all(values []bool) bool {
+	for _, value := range values {
+		if !value {
+			return false
+		}
+	}
+	return true
+}
+
+// anyTrue reports whether at least one value is true
+func anyTrue(values []bool) bool {
+	for _, value := range values {
+		if value {
+			return true
+		}
+	}
+	return false
+}
+
+// callExpr builds a call to the named function
+func callExpr(name string, args []ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{Fun: ast.NewIdent(name), Args: args}
+}
@@ -0,0 +1,217 @@
+package sat
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestIte(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	checkTruthTable(t, "ite(a, b, c)", symbols, func(v []bool) bool {
+		if v[0] {
+			return v[1]
+		}
+		return v[2]
+	})
+	checkTruthTable(t, "!ite(a, b || c, !c)", symbols, func(v []bool) bool {
+		if v[0] {
+			return !(v[1] || v[2])
+		}
+		return v[2]
+	})
+
+	for _, formula := range []string{"ite(a, b)", "ite(a, b, c, a)"} {
+		if _, err := Eval(formula, map[string]bool{"a": true, "b": true, "c": true}); err == nil {
+			t.Errorf("%s: expected an error for the number of arguments", formula)
+		}
+	}
+}
+
+func TestNandNor(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	checkTruthTable(t, "nand(a, b)", symbols, func(v []bool) bool { return !(v[0] && v[1]) })
+	checkTruthTable(t, "nand(a, b, c)", symbols, func(v []bool) bool { return !(v[0] && v[1] && v[2]) })
+	checkTruthTable(t, "nor(a, b)", symbols, func(v []bool) bool { return !(v[0] || v[1]) })
+	checkTruthTable(t, "nor(a, b, c)", symbols, func(v []bool) bool { return !(v[0] || v[1] || v[2]) })
+	checkTruthTable(t, "nand(a, a) == !a && nor(a, a) == !a", symbols, func(v []bool) bool { return true })
+
+	for _, formula := range []string{"nand(a)", "nor(a)"} {
+		if _, err := Eval(formula, map[string]bool{"a": true}); err == nil {
+			t.Errorf("%s: expected an error for the single argument", formula)
+		}
+	}
+}
+
+func TestXnor(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	checkTruthTable(t, "xnor(a, b)", symbols, func(v []bool) bool { return v[0] == v[1] })
+	checkTruthTable(t, "xnor(a, b) == (a <-> b)", symbols, func(v []bool) bool { return true })
+	// With more arguments all of them must be equal
+	checkTruthTable(t, "xnor(a, b, c)", symbols, func(v []bool) bool { return v[0] == v[1] && v[1] == v[2] })
+	checkTruthTable(t, "xnor(a, b, c, d)", symbols, func(v []bool) bool {
+		return v[0] == v[1] && v[1] == v[2] && v[2] == v[3]
+	})
+	checkTruthTable(t, "xnor(a, !a, b)", symbols, func(v []bool) bool { return false })
+	checkTruthTable(t, "xnor(a, true, b)", symbols, func(v []bool) bool { return v[0] && v[1] })
+
+	if _, err := Eval("xnor(a)", map[string]bool{"a": true}); err == nil {
+		t.Errorf("xnor(a): expected an error for the single argument")
+	}
+}
+
+// TestBuiltins checks each registered function, with each number of arguments
+// up to four, against its evaluation, its compiled form and its lowering
+func TestBuiltins(t *testing.T) {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := builtins[name]
+		for n := f.minArgs; n <= 4 && (f.maxArgs < 0 || n <= f.maxArgs); n++ {
+			// The cardinality constraints are tried with every count
+			calls := []boundCall{{f: f, operands: identList(manySymbols(n))}}
+			if f.count != nil {
+				calls = nil
+				for k := 0; k <= n; k++ {
+					calls = append(calls, boundCall{f: f, k: k, operands: identList(manySymbols(n - 1))})
+				}
+			}
+
+			for _, c := range calls {
+				symbols := manySymbols(len(c.operands))
+				args := c.operands
+				if f.count != nil {
+					args = append([]ast.Expr{&ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(c.k)}}, args...)
+				}
+				call := callExpr(name, args)
+				formula := formatExpr(call)
+				compiled, err := Compile(formula, symbols)
+				if err != nil {
+					t.Fatalf("%s: %v", formula, err)
+				}
+				lowered, err := lowerCall(call, false)
+				if err != nil {
+					t.Fatalf("%s: %v", formula, err)
+				}
+				negated, err := lowerCall(call, true)
+				if err != nil {
+					t.Fatalf("%s: %v", formula, err)
+				}
+
+				checkTruthTable(t, formula, symbols, c.apply)
+				checkTruthTable(t, formatExpr(lowered), symbols, c.apply)
+				checkTruthTable(t, formatExpr(negated), symbols, func(v []bool) bool { return !c.apply(v) })
+				for i := 0; i < 1<<len(symbols); i++ {
+					v := make([]bool, len(symbols))
+					for j := range v {
+						v[j] = (i>>j)&1 == 1
+					}
+					if got, want := compiled(v), c.apply(v); got != want {
+						t.Errorf("compiled %s on %v: got %t, want %t", formula, v, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBuiltinErrors(t *testing.T) {
+	values := map[string]bool{"a": true, "b": false}
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"foo(a, b)", "unknown function 'foo'"},
+		{"a && bar(b)", "unknown function 'bar'"},
+		{"ite(a, b)", "ite: expected 3 arguments, found 2"},
+		{"xor(a)", "xor: expected at least 2 arguments, found 1"},
+	}
+	for _, test := range tests {
+		_, err := Eval(test.formula, values)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("eval %s: got error %v, want %q", test.formula, err, test.want)
+		}
+		_, err = Compile(test.formula, []string{"a", "b"})
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("compile %s: got error %v, want %q", test.formula, err, test.want)
+		}
+	}
+}
+
+func TestLowerCallTooManyArgs(t *testing.T) {
+	// The cardinality constraints expand into a formula polynomial in the
+	// number of their operands, the other builtins don't
+	symbols := make([]string, 64)
+	for i := range symbols {
+		symbols[i] = "s" + strconv.Itoa(i)
+	}
+	list := strings.Join(symbols, ", ")
+	for _, formula := range []string{"xor(" + list + ")", "majority(" + list + ")"} {
+		want := "too many arguments to rewrite into the operators"
+		if _, err := ToCNF(formula); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ToCNF: got error %v, want %q", err, want)
+		}
+		if _, err := ToNNF(formula); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ToNNF: got error %v, want %q", err, want)
+		}
+	}
+	if _, err := ToNNF("atleast(2, " + list + ")"); err != nil {
+		t.Errorf("ToNNF atleast: got error %v", err)
+	}
+}
+
+// identList returns the symbols as identifiers
+func identList(symbols []string) []ast.Expr {
+	idents := make([]ast.Expr, len(symbols))
+	for i, symbol := range symbols {
+		idents[i] = ast.NewIdent(symbol)
+	}
+	return idents
+}
+
+func TestMajority(t *testing.T) {
+	tests := []struct {
+		formula string
+		values  map[string]bool
+		want    bool
+	}{
+		{"majority(a, b, c)", map[string]bool{"a": true, "b": true, "c": false}, true},
+		{"majority(a, b, c)", map[string]bool{"a": false, "b": true, "c": false}, false},
+		{"majority(a, b, c)", map[string]bool{"a": true, "b": true, "c": true}, true},
+		{"majority(a, b, c)", map[string]bool{"a": false, "b": false, "c": false}, false},
+		{"majority(a, b, c, d, e)", map[string]bool{"a": true, "b": false, "c": true, "d": false, "e": true}, true},
+		{"majority(a, b, c, d, e)", map[string]bool{"a": true, "b": false, "c": false, "d": false, "e": true}, false},
+		{"majority(a, b, c, d, e)", map[string]bool{"a": true, "b": true, "c": true, "d": true, "e": false}, true},
+		// Ties are false
+		{"majority(a, b)", map[string]bool{"a": true, "b": false}, false},
+		{"majority(a, b, c, d)", map[string]bool{"a": true, "b": true, "c": false, "d": false}, false},
+		{"majority(a, b, c, d)", map[string]bool{"a": true, "b": true, "c": true, "d": false}, true},
+	}
+	for _, test := range tests {
+		got, err := Eval(test.formula, test.values)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if got != test.want {
+			t.Errorf("%s on %v: got %t, want %t", test.formula, test.values, got, test.want)
+		}
+	}
+
+	symbols := manySymbols(5)
+	checkTruthTable(t, "majority("+strings.Join(symbols, ", ")+")", symbols, func(v []bool) bool {
+		nTrue := 0
+		for _, value := range v {
+			if value {
+				nTrue++
+			}
+		}
+		return nTrue >= 3
+	})
+}
@@ -0,0 +1,287 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// literal is a symbol, possibly negated
+type literal struct {
+	name    string
+	negated bool
+}
+
+// clause is a disjunction of literals, false when empty
+type clause []literal
+
+// cnf is a conjunction of clauses, true when empty
+type cnf []clause
+
+// polarNode is a node of an expression, to be converted either as it is or
+// negated
+type polarNode struct {
+	node    ast.Expr
+	negated bool
+}
+
+// ToCNF returns a formula in conjunctive normal form equivalent to the given
+// one, by pushing the negations down to the symbols and distributing the
+// disjunctions over the conjunctions
+func ToCNF(formula string) (string, error) {
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return "", err
+	}
+	clauses, err := toCNF(expr, false)
+	if err != nil {
+		return "", err
+	}
+	return clauses.String(), nil
+}
+
+// ToDNF returns a formula in disjunctive normal form equivalent to the given
+// one. The terms of the DNF of a formula are the negated clauses of the CNF
+// of its negation, so they come out of the same conversion
+func ToDNF(formula string) (string, error) {
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return "", err
+	}
+	clauses, err := toCNF(expr, true)
+	if err != nil {
+		return "", err
+	}
+
+	terms := make([][]literal, len(clauses))
+	for i, c := range clauses {
+		terms[i] = make([]literal, len(c))
+		for j, l := range c {
+			terms[i][j] = literal{name: l.name, negated: !l.negated}
+		}
+	}
+	return sumOfProducts(terms), nil
+}
+
+// toCNF converts the expression, or its negation if negated, to CNF
+func toCNF(node ast.Expr, negated bool) (cnf, error) {
+	return cnfMemo{}.toCNF(node, negated)
+}
+
+// cnfMemo holds the CNF of the nodes already converted, so that the shared
+// nodes of an expanded cardinality constraint are only converted once
+type cnfMemo map[polarNode]cnf
+
+// toCNF returns the memoized conversion of the expression, or of its negation
+// if negated
+func (m cnfMemo) toCNF(node ast.Expr, negated bool) (cnf, error) {
+	key := polarNode{node: node, negated: negated}
+	if result, ok := m[key]; ok {
+		return result, nil
+	}
+	result, err := m.convert(node, negated)
+	if err != nil {
+		return nil, err
+	}
+	m[key] = result
+	return result, nil
+}
+
+// convert converts the expression, or its negation if negated, walking its
+// operands through the memo
+func (m cnfMemo) convert(node ast.Expr, negated bool) (cnf, error) {
+	switch expr := node.(type) {
+	case *ast.Ident:
+		switch expr.Name {
+		case "true", "false":
+			// True has no clause to satisfy, false has an unsatisfiable one
+			if (expr.Name == "true") != negated {
+				return cnf{}, nil
+			}
+			return cnf{clause{}}, nil
+		}
+		return cnf{clause{{name: expr.Name, negated: negated}}}, nil
+
+	case *ast.UnaryExpr:
+		if expr.Op != token.NOT {
+			return nil, fmt.Errorf("unsupported unary operator: %s", expr.Op)
+		}
+		return m.toCNF(expr.X, !negated)
+
+	case *ast.BinaryExpr:
+		switch expr.Op {
+		case token.LAND, token.LOR:
+			x, err := m.toCNF(expr.X, negated)
+			if err != nil {
+				return nil, err
+			}
+			y, err := m.toCNF(expr.Y, negated)
+			if err != nil {
+				return nil, err
+			}
+
+			// By De Morgan a negated conjunction is a disjunction and
+			// vice versa
+			if (expr.Op == token.LAND) != negated {
+				return conjunction(x, y), nil
+			}
+			return distribute(x, y), nil
+
+		case token.XOR, token.NEQ, token.EQL:
+			// x != y is (x || y) && (!x || !y), while x == y is
+			// (!x || y) && (x || !y)
+			negations := [2][2]bool{{false, false}, {true, true}}
+			if (expr.Op == token.EQL) != negated {
+				negations = [2][2]bool{{true, false}, {false, true}}
+			}
+
+			result := cnf{}
+			for _, n := range negations {
+				x, err := m.toCNF(expr.X, n[0])
+				if err != nil {
+					return nil, err
+				}
+				y, err := m.toCNF(expr.Y, n[1])
+				if err != nil {
+					return nil, err
+				}
+				result = conjunction(result, distribute(x, y))
+			}
+			return result, nil
+
+		default:
+			return nil, fmt.Errorf("unsupported binary operator: %s", expr.Op)
+		}
+
+	case *ast.CallExpr:
+		lowered, err := lowerCall(expr, negated)
+		if err != nil {
+			return nil, err
+		}
+		return m.toCNF(lowered, false)
+
+	case *ast.ParenExpr:
+		return m.toCNF(expr.X, negated)
+
+	default:
+		return nil, fmt.Errorf("unsupported expression type: %T", node)
+	}
+}
+
+// conjunction returns the CNF of the conjunction of x and y, dropping the
+// clauses subsumed by another one, which are true whenever it is
+func conjunction(x, y cnf) cnf {
+	result := append(cnf{}, x...)
+	for _, c := range y {
+		result = addClause(result, c)
+	}
+	return result
+}
+
+// addClause adds the clause to the CNF unless one of its clauses subsumes it,
+// removing the clauses it subsumes in turn
+func addClause(f cnf, c clause) cnf {
+	for _, other := range f {
+		if other.subsumes(c) {
+			return f
+		}
+	}
+	result := f[:0]
+	for _, other := range f {
+		if !c.subsumes(other) {
+			result = append(result, other)
+		}
+	}
+	return append(result, c)
+}
+
+// distribute returns the CNF of the disjunction of x and y, joining each
+// clause of x with each clause of y
+func distribute(x, y cnf) cnf {
+	result := cnf{}
+	for _, cx := range x {
+		for _, cy := range y {
+			if c, ok := join(cx, cy); ok {
+				result = addClause(result, c)
+			}
+		}
+	}
+	return result
+}
+
+// join returns the disjunction of the two clauses without repeated literals,
+// and false if it is always true because it holds a literal and its negation
+func join(x, y clause) (clause, bool) {
+	c := append(clause{}, x...)
+	for _, l := range y {
+		duplicate := false
+		for _, other := range c {
+			if other.name == l.name {
+				if other.negated != l.negated {
+					return nil, false
+				}
+				duplicate = true
+			}
+		}
+		if !duplicate {
+			c = append(c, l)
+		}
+	}
+	return c, true
+}
+
+// subsumes reports whether every literal of c is also in d
+func (c clause) subsumes(d clause) bool {
+	if len(c) > len(d) {
+		return false
+	}
+	for _, l := range c {
+		found := false
+		for _, m := range d {
+			if l == m {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (l literal) String() string {
+	if l.negated {
+		return "!" + l.name
+	}
+	return l.name
+}
+
+func (c clause) String() string {
+	literals := make([]string, len(c))
+	for i, l := range c {
+		literals[i] = l.String()
+	}
+	return strings.Join(literals, " || ")
+}
+
+func (f cnf) String() string {
+	if len(f) == 0 {
+		return "true"
+	}
+
+	clauses := make([]string, len(f))
+	for i, c := range f {
+		switch {
+		case len(c) == 0:
+			// A single unsatisfiable clause falsifies the whole formula
+			return "false"
+		case len(c) > 1 && len(f) > 1:
+			clauses[i] = "(" + c.String() + ")"
+		default:
+			clauses[i] = c.String()
+		}
+	}
+	return strings.Join(clauses, " && ")
+}
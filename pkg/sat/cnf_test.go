@@ -0,0 +1,110 @@
+package sat
+
+import (
+	"strings"
+	"testing"
+)
+
+// isCNF reports whether the formula is a conjunction of disjunctions of
+// literals
+func isCNF(formula string) bool {
+	for _, c := range strings.Split(formula, " && ") {
+		c = strings.TrimSuffix(strings.TrimPrefix(c, "("), ")")
+		if strings.ContainsAny(c, "()&^=<>-") {
+			return false
+		}
+	}
+	return true
+}
+
+func TestToCNF(t *testing.T) {
+	for _, formula := range []string{
+		"a -> b",
+		"!(a && b) || c",
+		"a ^ b",
+		"a <-> b",
+		"(a || b) && !c",
+		"a && (b || c && !a)",
+		"!(a ^ b ^ c)",
+		"atleast(2, a, b, c)",
+		"ite(a, b, c)",
+		"true",
+		"false",
+	} {
+		cnf, err := ToCNF(formula)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		if !isCNF(cnf) {
+			t.Errorf("%s: %s is not in CNF", formula, cnf)
+		}
+		ok, witness, err := Equivalent(formula, cnf, []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("%s: %s disagrees on %v", formula, cnf, witness)
+		}
+	}
+
+	cnf, err := ToCNF("a ^ b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(a || b) && (!a || !b)"; cnf != want {
+		t.Errorf("a ^ b: got %s, want %s", cnf, want)
+	}
+}
+
+// isDNF reports whether the formula is a disjunction of conjunctions of
+// literals
+func isDNF(formula string) bool {
+	for _, term := range strings.Split(formula, " || ") {
+		term = strings.TrimSuffix(strings.TrimPrefix(term, "("), ")")
+		if strings.ContainsAny(term, "()|^=<>-") {
+			return false
+		}
+	}
+	return true
+}
+
+func TestToDNF(t *testing.T) {
+	tests := []struct {
+		formula string
+		terms   int
+	}{
+		{"a ^ b", 2},
+		{"a <-> b", 2},
+		{"a ^ b ^ c", 4},
+		{"atleast(2, a, b, c)", 3},
+		{"(a || b) && c", 2},
+		{"!(a || b || c)", 1},
+		{"a -> b", 2},
+		{"!(a && (b || !c))", 2},
+	}
+	for _, tt := range tests {
+		dnf, err := ToDNF(tt.formula)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.formula, err)
+		}
+		if !isDNF(dnf) {
+			t.Errorf("%s: %s is not in DNF", tt.formula, dnf)
+		}
+		if terms := len(strings.Split(dnf, " || ")); terms != tt.terms {
+			t.Errorf("%s: got %s with %d terms, want %d", tt.formula, dnf, terms, tt.terms)
+		}
+		ok, witness, err := Equivalent(tt.formula, dnf, []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("%s: %s disagrees on %v", tt.formula, dnf, witness)
+		}
+	}
+
+	for formula, want := range map[string]string{"true": "true", "false": "false", "a && !a": "false"} {
+		if dnf, err := ToDNF(formula); err != nil || dnf != want {
+			t.Errorf("%s: got %s, %v, want %s", formula, dnf, err, want)
+		}
+	}
+}
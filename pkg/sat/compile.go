@@ -0,0 +1,171 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// compile turns the formula into a closure evaluating it on a combination
+// packed into the bits of a uint64, where bit j holds the value of symbols[j].
+// This skips the map lookups and the AST walk of evalExpr in the hot loop
+func compile(expr ast.Expr, symbols []string) (func(uint64) bool, error) {
+	if err := checkConstants(symbols); err != nil {
+		return nil, err
+	}
+	bits := make(map[string]uint, len(symbols))
+	for j, symbol := range symbols {
+		bits[symbol] = uint(j)
+	}
+	return compileNode(expr, bits)
+}
+
+// maxTabulatedArgs is the most arguments of a builtin call for which its
+// truth table is computed upfront, sparing an allocation at each call
+const maxTabulatedArgs = 12
+
+// Compile compiles the formula into a function evaluating it on the values
+// of the symbols, held at their position in the slice. The function doesn't
+// allocate, so it suits the hot loops of the callers enumerating the
+// combinations on their own. Missing values count as false
+func Compile(formula string, symbols []string) (func(values []bool) bool, error) {
+	if len(symbols) > 64 {
+		return nil, fmt.Errorf("too many symbols to compile (max 64): %d", len(symbols))
+	}
+	// The positions must be unambiguous
+	symbols, repeated := uniqueSymbols(symbols)
+	if repeated != nil {
+		return nil, fmt.Errorf("%s in the input values", describeRepeated(repeated))
+	}
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return nil, err
+	}
+	eval, err := compile(expr, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(symbols)
+	return func(values []bool) bool {
+		var c uint64
+		for j, value := range values {
+			if j < n && value {
+				c |= 1 << j
+			}
+		}
+		return eval(c)
+	}, nil
+}
+
+func compileNode(node ast.Expr, bits map[string]uint) (func(uint64) bool, error) {
+	switch expr := node.(type) {
+	case *ast.Ident:
+		// Symbols read their bit, falling back to the boolean literals
+		if j, ok := bits[expr.Name]; ok {
+			return func(c uint64) bool { return (c>>j)&1 == 1 }, nil
+		}
+		switch expr.Name {
+		case "true":
+			return func(uint64) bool { return true }, nil
+		case "false":
+			return func(uint64) bool { return false }, nil
+		}
+		return nil, fmt.Errorf("identifier '%s' not found in input values", expr.Name)
+
+	case *ast.UnaryExpr:
+		if expr.Op != token.NOT {
+			return nil, fmt.Errorf("unsupported unary operator: %s", expr.Op)
+		}
+		x, err := compileNode(expr.X, bits)
+		if err != nil {
+			return nil, err
+		}
+		return func(c uint64) bool { return !x(c) }, nil
+
+	case *ast.BinaryExpr:
+		x, err := compileNode(expr.X, bits)
+		if err != nil {
+			return nil, err
+		}
+		y, err := compileNode(expr.Y, bits)
+		if err != nil {
+			return nil, err
+		}
+
+		switch expr.Op {
+		case token.LAND:
+			return func(c uint64) bool { return x(c) && y(c) }, nil
+		case token.LOR:
+			return func(c uint64) bool { return x(c) || y(c) }, nil
+		case token.XOR, token.NEQ:
+			return func(c uint64) bool { return x(c) != y(c) }, nil
+		case token.EQL:
+			return func(c uint64) bool { return x(c) == y(c) }, nil
+		default:
+			return nil, fmt.Errorf("unsupported binary operator: %s", expr.Op)
+		}
+
+	case *ast.CallExpr:
+		call, err := resolveCall(expr)
+		if err != nil {
+			return nil, err
+		}
+		args := make([]func(uint64) bool, len(call.operands))
+		for i, arg := range call.operands {
+			if args[i], err = compileNode(arg, bits); err != nil {
+				return nil, err
+			}
+		}
+
+		// The cardinality constraints only need to count the true operands
+		if count := call.f.count; count != nil {
+			k := call.k
+			return func(c uint64) bool {
+				nTrue := 0
+				for _, arg := range args {
+					if arg(c) {
+						nTrue++
+					}
+				}
+				return count(k, nTrue)
+			}, nil
+		}
+
+		// Look the result up in the truth table of the function, indexed
+		// by the arguments packed into bits, unless it would be too large
+		if len(args) <= maxTabulatedArgs {
+			table := make([]bool, 1<<len(args))
+			values := make([]bool, len(args))
+			for i := range table {
+				for j := range values {
+					values[j] = (i>>j)&1 == 1
+				}
+				table[i] = call.apply(values)
+			}
+			return func(c uint64) bool {
+				i := 0
+				for j, arg := range args {
+					if arg(c) {
+						i |= 1 << j
+					}
+				}
+				return table[i]
+			}, nil
+		}
+		return func(c uint64) bool {
+			// The workers share the closure, so each call needs its own slice
+			values := make([]bool, len(args))
+			for i, arg := range args {
+				values[i] = arg(c)
+			}
+			return call.apply(values)
+		}, nil
+
+	case *ast.ParenExpr:
+		return compileNode(expr.X, bits)
+
+	default:
+		return nil, fmt.Errorf("unsupported expression type: %T", node)
+	}
+}
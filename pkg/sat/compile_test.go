@@ -0,0 +1,158 @@
+package sat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCompileAgreesWithEval(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d", "e"}
+	rng := rand.New(rand.NewSource(1))
+	for seed := int64(1); seed <= 200; seed++ {
+		formula := RandomFormula(symbols, 5, seed)
+		expr, err := parseFormula(formula)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		eval, err := compile(expr, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		for i := 0; i < 8; i++ {
+			c := rng.Intn(1 << len(symbols))
+			values := combination(c, symbols)
+			want, err := evalBoolExpr(formula, values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := eval(uint64(c)); got != want {
+				t.Errorf("%s on %v: compiled to %t, evaluates to %t", formula, values, got, want)
+			}
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, formula := range []string{"a && z", "a + b", "unknown(a)"} {
+		expr, err := parseSyntax(formula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := compile(expr, []string{"a", "b"}); err == nil {
+			t.Errorf("%s: expected an error", formula)
+		}
+	}
+}
+
+// BenchmarkCompiled is BenchmarkEvalParsed with the compiled closure
+func BenchmarkCompiled(b *testing.B) {
+	symbols := manySymbols(18)
+	expr, err := parseFormula(benchFormula)
+	if err != nil {
+		b.Fatal(err)
+	}
+	eval, err := compile(expr, symbols)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for c := uint64(0); c < 1<<len(symbols); c++ {
+			eval(c)
+		}
+	}
+}
+
+func TestCompileSlice(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	for seed := int64(1); seed <= 200; seed++ {
+		formula := RandomFormula(symbols, 4, seed)
+		eval, err := Compile(formula, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		values := make([]bool, len(symbols))
+		for c := 0; c < 1<<len(symbols); c++ {
+			for j := range values {
+				values[j] = (c>>j)&1 == 1
+			}
+			want, err := Eval(formula, combination(c, symbols))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := eval(values); got != want {
+				t.Errorf("%s on %v: compiled to %t, evaluates to %t", formula, values, got, want)
+			}
+		}
+	}
+
+	// Missing values are false, and the ones past the symbols are ignored
+	eval, err := Compile("!a && !b", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !eval(nil) || !eval([]bool{false}) || !eval([]bool{false, false, true}) || eval([]bool{false, true}) {
+		t.Errorf("!a && !b: got the wrong results for short or long values")
+	}
+}
+
+func TestCompileSliceAllocations(t *testing.T) {
+	symbols := manySymbols(18)
+	for _, formula := range []string{benchFormula, "atleast(3, s0, s1, s2, s3) && ite(s4, s5, s6)"} {
+		eval, err := Compile(formula, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := make([]bool, len(symbols))
+		c := 0
+		allocs := testing.AllocsPerRun(100, func() {
+			for j := range values {
+				values[j] = (c>>j)&1 == 1
+			}
+			eval(values)
+			c++
+		})
+		if allocs != 0 {
+			t.Errorf("%s: got %v allocations per evaluation, want none", formula, allocs)
+		}
+	}
+}
+
+func TestCompileSliceErrors(t *testing.T) {
+	tests := []struct {
+		formula string
+		symbols []string
+	}{
+		{"a && z", []string{"a", "b"}},
+		{"a + b", []string{"a", "b"}},
+		{"a &&", []string{"a"}},
+		{"a && b", []string{"a", "b", "a"}},
+		{"s0", manySymbols(65)},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(tt.formula, tt.symbols); err == nil {
+			t.Errorf("%s on %d symbols: expected an error", tt.formula, len(tt.symbols))
+		}
+	}
+}
+
+// BenchmarkCompiledSlice is BenchmarkEvalParsed with the values in a slice,
+// to be compared with -benchmem
+func BenchmarkCompiledSlice(b *testing.B) {
+	symbols := manySymbols(18)
+	eval, err := Compile(benchFormula, symbols)
+	if err != nil {
+		b.Fatal(err)
+	}
+	values := make([]bool, len(symbols))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for c := 0; c < 1<<len(symbols); c++ {
+			for j := range values {
+				values[j] = (c>>j)&1 == 1
+			}
+			eval(values)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package sat
+
+import (
+	"fmt"
+	"testing"
+)
+
+// crosscheckSymbols and crosscheckDepth shape the random formulas of
+// TestCrossCheck
+var crosscheckSymbols = []string{"a", "b", "c", "d", "e"}
+
+const crosscheckDepth = 6
+
+// crossCheck solves the formula with the brute force search, DPLL and the
+// BDD, failing with the first disagreement between them
+func crossCheck(formula string, symbols []string) error {
+	count, err := CountSolutions(formula, symbols)
+	if err != nil {
+		return fmt.Errorf("brute force: %w", err)
+	}
+
+	model, err := SolveDPLL(formula, symbols)
+	if err != nil {
+		return fmt.Errorf("DPLL: %w", err)
+	}
+	if (model != nil) != (count > 0) {
+		return fmt.Errorf("DPLL satisfiable is %t, brute force counts %d solutions", model != nil, count)
+	}
+	if model != nil {
+		res, err := Eval(formula, model)
+		if err != nil {
+			return fmt.Errorf("DPLL: %w", err)
+		}
+		if !res {
+			return fmt.Errorf("DPLL model %v doesn't satisfy the formula", model)
+		}
+	}
+
+	bdd, err := BuildBDD(formula, symbols)
+	if err != nil {
+		return fmt.Errorf("BDD: %w", err)
+	}
+	if n := bdd.SatCount(); n != count {
+		return fmt.Errorf("BDD counts %d solutions, brute force %d", n, count)
+	}
+	return nil
+}
+
+// TestCrossCheck makes sure that the engines agree on the random formulas of
+// the seeds from 1 up, reporting the seed of each disagreement so that it can
+// be reproduced with RandomFormula
+func TestCrossCheck(t *testing.T) {
+	seeds := int64(1000)
+	if testing.Short() {
+		seeds = 100
+	}
+	for seed := int64(1); seed <= seeds; seed++ {
+		formula := RandomFormula(crosscheckSymbols, crosscheckDepth, seed)
+		if err := crossCheck(formula, crosscheckSymbols); err != nil {
+			t.Errorf("seed %d: %s: %v", seed, formula, err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ToDIMACS converts the formula to CNF and encodes it in the DIMACS format
+// read by SAT solvers like MiniSat. The symbols are numbered from 1 in sorted
+// order, and a comment line before the header maps each number to its symbol
+func ToDIMACS(formula string) (string, error) {
+	symbols, err := ExtractSymbols(formula)
+	if err != nil {
+		return "", err
+	}
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return "", err
+	}
+	clauses, err := toCNF(expr, false)
+	if err != nil {
+		return "", err
+	}
+
+	variables := make(map[string]int, len(symbols))
+	var b strings.Builder
+	for i, symbol := range symbols {
+		variables[symbol] = i + 1
+		fmt.Fprintf(&b, "c %d %s\n", i+1, symbol)
+	}
+
+	fmt.Fprintf(&b, "p cnf %d %d\n", len(symbols), len(clauses))
+	for _, c := range clauses {
+		for _, l := range c {
+			if l.negated {
+				b.WriteByte('-')
+			}
+			fmt.Fprintf(&b, "%d ", variables[l.name])
+		}
+		b.WriteString("0\n")
+	}
+
+	return b.String(), nil
+}
+
+// ParseDIMACS reads a DIMACS CNF instance and rebuilds it as a formula over
+// the symbols x1 to xn, where n is the number of variables in the header
+func ParseDIMACS(r io.Reader) (string, []string, error) {
+	var symbols []string
+	var clauses []string
+	var literals []string
+	nClauses := -1
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if fields[0] == "%" {
+			// Some benchmark suites end the instance with a '%' line
+			break
+		}
+
+		if fields[0] == "p" {
+			if symbols != nil || len(fields) != 4 || fields[1] != "cnf" {
+				return "", nil, fmt.Errorf("line %d: invalid problem line", line)
+			}
+			nVars, err := strconv.Atoi(fields[2])
+			if err != nil || nVars < 0 {
+				return "", nil, fmt.Errorf("line %d: invalid number of variables", line)
+			}
+			if nClauses, err = strconv.Atoi(fields[3]); err != nil || nClauses < 0 {
+				return "", nil, fmt.Errorf("line %d: invalid number of clauses", line)
+			}
+
+			symbols = make([]string, nVars)
+			for i := range symbols {
+				symbols[i] = fmt.Sprintf("x%d", i+1)
+			}
+			continue
+		}
+		if symbols == nil {
+			return "", nil, fmt.Errorf("line %d: clause before the problem line", line)
+		}
+
+		// Clauses end with a 0 and may span several lines
+		for _, field := range fields {
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return "", nil, fmt.Errorf("line %d: invalid literal '%s'", line, field)
+			}
+			switch {
+			case n == 0:
+				clauses = append(clauses, dimacsClause(literals))
+				literals = nil
+			case n > len(symbols) || -n > len(symbols):
+				return "", nil, fmt.Errorf("line %d: variable %d out of range", line, n)
+			case n > 0:
+				literals = append(literals, symbols[n-1])
+			default:
+				literals = append(literals, "!"+symbols[-n-1])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+
+	if symbols == nil {
+		return "", nil, fmt.Errorf("missing problem line")
+	}
+	if len(literals) > 0 {
+		// Tolerate a missing 0 after the last clause
+		clauses = append(clauses, dimacsClause(literals))
+	}
+	if len(clauses) != nClauses {
+		return "", nil, fmt.Errorf("expected %d clauses, found %d", nClauses, len(clauses))
+	}
+	if len(clauses) == 0 {
+		return "true", symbols, nil
+	}
+
+	return strings.Join(clauses, " && "), symbols, nil
+}
+
+// dimacsClause joins the literals of a clause into a disjunction
+func dimacsClause(literals []string) string {
+	if len(literals) == 0 {
+		return "false"
+	}
+	return "(" + strings.Join(literals, " || ") + ")"
+}
@@ -0,0 +1,97 @@
+package sat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToDIMACS(t *testing.T) {
+	got, err := ToDIMACS("(a || b) && !c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "c 1 a\nc 2 b\nc 3 c\np cnf 3 2\n1 2 0\n-3 0\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseDIMACS(t *testing.T) {
+	tests := []struct {
+		name        string
+		instance    string
+		satisfiable bool
+	}{
+		{"sat", "c a small satisfiable instance\np cnf 3 3\n1 -2 0\n2 3 0\n-1 0\n", true},
+		{"unsat", "p cnf 2 4\n1 2 0\n-1 2 0\n1 -2 0\n-1 -2 0\n", false},
+		{"multiline clause", "p cnf 3 1\n1\n2 3\n0\n", true},
+		{"empty clause", "p cnf 1 1\n0\n", false},
+		{"no clauses", "p cnf 2 0\n", true},
+		{"percent trailer", "p cnf 1 1\n1 0\n%\n0\n", true},
+	}
+	for _, test := range tests {
+		formula, symbols, err := ParseDIMACS(strings.NewReader(test.instance))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		result, err := Solve(formula, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if result.Satisfiable != test.satisfiable {
+			t.Errorf("%s: %s got satisfiable %t, want %t", test.name, formula, result.Satisfiable, test.satisfiable)
+		}
+	}
+
+	formula, symbols, err := ParseDIMACS(strings.NewReader("p cnf 3 2\n1 -3 0\n2 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(x1 || !x3) && (x2)"; formula != want {
+		t.Errorf("got formula %s, want %s", formula, want)
+	}
+	if len(symbols) != 3 {
+		t.Errorf("got symbols %v, want x1 to x3", symbols)
+	}
+}
+
+func TestParseDIMACSErrors(t *testing.T) {
+	for _, instance := range []string{
+		"",
+		"1 2 0\n",
+		"p cnf x 1\n1 0\n",
+		"p dnf 1 1\n1 0\n",
+		"p cnf 1 1\n2 0\n",
+		"p cnf 1 2\n1 0\n",
+		"p cnf 1 1\na 0\n",
+		"p cnf 1 1\np cnf 1 1\n1 0\n",
+	} {
+		if _, _, err := ParseDIMACS(strings.NewReader(instance)); err == nil {
+			t.Errorf("%q: expected an error", instance)
+		}
+	}
+}
+
+func TestDIMACSRoundTrip(t *testing.T) {
+	for _, formula := range []string{"(a || b) && !c", "a ^ b ^ c", "a -> b -> c"} {
+		dimacs, err := ToDIMACS(formula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, symbols, err := ParseDIMACS(strings.NewReader(dimacs))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := CountSolutions(formula, []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := CountSolutions(parsed, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s: the instance has %d solutions, the formula %d", formula, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// ToDOT renders the parsed formula as a Graphviz digraph, with a node for
+// every operator and identifier and an edge to each of its operands
+func ToDOT(formula string) (string, error) {
+	expr, err := parseChecked(formula)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph formula {\n")
+	if _, err := writeDOT(&b, expr, new(int)); err != nil {
+		return "", err
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// writeDOT writes the node of the expression and those below it, numbering
+// them with the counter, and returns the name of the node. Parentheses only
+// group and get no node of their own
+func writeDOT(b *strings.Builder, node ast.Expr, counter *int) (string, error) {
+	var label string
+	var children []ast.Expr
+	switch expr := node.(type) {
+	case *ast.Ident:
+		label = expr.Name
+	case *ast.BasicLit:
+		// The count of a cardinality constraint
+		label = expr.Value
+	case *ast.UnaryExpr:
+		label, children = expr.Op.String(), []ast.Expr{expr.X}
+	case *ast.BinaryExpr:
+		label, children = operatorString(expr.Op), []ast.Expr{expr.X, expr.Y}
+	case *ast.CallExpr:
+		ident, ok := expr.Fun.(*ast.Ident)
+		if !ok {
+			return "", fmt.Errorf("unsupported function: %T", expr.Fun)
+		}
+		label, children = ident.Name, expr.Args
+	case *ast.ParenExpr:
+		return writeDOT(b, expr.X, counter)
+	default:
+		return "", fmt.Errorf("unsupported expression type: %T", node)
+	}
+
+	name := fmt.Sprintf("n%d", *counter)
+	*counter++
+	fmt.Fprintf(b, "\t%s [label=%q];\n", name, label)
+
+	for _, child := range children {
+		childName, err := writeDOT(b, child, counter)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(b, "\t%s -> %s;\n", name, childName)
+	}
+	return name, nil
+}
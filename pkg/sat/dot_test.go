@@ -0,0 +1,66 @@
+package sat
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestToDOT(t *testing.T) {
+	dot, err := ToDOT("a && (b || !c)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(dot, "digraph formula {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Errorf("got %q, want a digraph", dot)
+	}
+
+	var labels []string
+	for _, match := range regexp.MustCompile(`label="([^"]*)"`).FindAllStringSubmatch(dot, -1) {
+		labels = append(labels, match[1])
+	}
+	sort.Strings(labels)
+	if want := []string{"!", "&&", "a", "b", "c", "||"}; strings.Join(labels, " ") != strings.Join(want, " ") {
+		t.Errorf("got labels %q, want %q", labels, want)
+	}
+	// A tree of six nodes, the parentheses getting none
+	if edges := strings.Count(dot, "->"); edges != 5 {
+		t.Errorf("got %d edges, want 5", edges)
+	}
+}
+
+func TestToDOTErrors(t *testing.T) {
+	if _, err := ToDOT("a &&"); err == nil {
+		t.Errorf("expected an error for a formula that doesn't parse")
+	}
+}
+
+func TestToDOTAsWritten(t *testing.T) {
+	// The implication and the call are drawn as written, not rewritten
+	dot, err := ToDOT("a -> atleast(1, b, c)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph formula {
+	n0 [label="->"];
+	n1 [label="a"];
+	n0 -> n1;
+	n2 [label="atleast"];
+	n3 [label="1"];
+	n2 -> n3;
+	n4 [label="b"];
+	n2 -> n4;
+	n5 [label="c"];
+	n2 -> n5;
+	n0 -> n2;
+}
+`
+	if dot != want {
+		t.Errorf("got\n%s\nwant\n%s", dot, want)
+	}
+
+	if _, err := ToDOT("a -> unknown(b)"); err == nil {
+		t.Errorf("expected an error for an unknown function")
+	}
+}
@@ -0,0 +1,147 @@
+package sat
+
+// SolveDPLL encodes the formula into clauses with the Tseitin
+// transformation and looks for a satisfying combination of the symbols with
+// the DPLL algorithm, returning nil if the formula is unsatisfiable. Unlike
+// the brute force search, it doesn't have to go through all the 2^n
+// combinations
+func SolveDPLL(formula string, symbols []string) (map[string]bool, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSymbols(expr, symbols); err != nil {
+		return nil, err
+	}
+	t := newTseitin(symbols)
+	if err := t.assert(expr); err != nil {
+		return nil, err
+	}
+
+	assignment := make([]int8, t.next)
+	if !dpll(t.clauses, assignment) {
+		return nil, nil
+	}
+
+	// The variables left unassigned don't matter, so they default to false.
+	// Those of the operators are left out
+	model := make(map[string]bool, len(symbols))
+	for i, symbol := range symbols {
+		model[symbol] = assignment[i+1] > 0
+	}
+	return model, nil
+}
+
+// dpll looks for an assignment satisfying the numbered clauses, where
+// assignment[v] is 1 if v is true, -1 if it is false and 0 if unassigned. On
+// success the assignment is left holding the solution
+func dpll(clauses [][]int, assignment []int8) bool {
+	// Propagate the unit clauses until nothing changes
+	for changed := true; changed; {
+		changed = false
+		for _, c := range clauses {
+			satisfied, unassigned, last := clauseState(c, assignment)
+			if satisfied {
+				continue
+			}
+			if unassigned == 0 {
+				return false
+			}
+			if unassigned == 1 {
+				assign(assignment, last)
+				changed = true
+			}
+		}
+	}
+
+	// Assign the pure literals, which only appear with a single polarity
+	polarity := make([]int8, len(assignment))
+	open := false
+	for _, c := range clauses {
+		if satisfied, _, _ := clauseState(c, assignment); satisfied {
+			continue
+		}
+		open = true
+		for _, l := range c {
+			v, sign := variable(l)
+			if assignment[v] != 0 {
+				continue
+			}
+			switch polarity[v] {
+			case 0:
+				polarity[v] = sign
+			case -sign:
+				polarity[v] = 2
+			}
+		}
+	}
+	if !open {
+		return true
+	}
+	for v, p := range polarity {
+		if p == 1 || p == -1 {
+			assignment[v] = p
+		}
+	}
+
+	// Branch on the first variable of a clause that is still open
+	for _, c := range clauses {
+		if satisfied, _, _ := clauseState(c, assignment); satisfied {
+			continue
+		}
+		for _, l := range c {
+			v, _ := variable(l)
+			if assignment[v] != 0 {
+				continue
+			}
+			for _, value := range []int8{1, -1} {
+				branch := append([]int8{}, assignment...)
+				branch[v] = value
+				if dpll(clauses, branch) {
+					copy(assignment, branch)
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	// Only reached when the pure literals satisfied every clause
+	return true
+}
+
+// clauseState reports whether the clause is already satisfied and, if not,
+// how many of its literals are unassigned and which is the last one of them
+func clauseState(c []int, assignment []int8) (bool, int, int) {
+	unassigned, last := 0, 0
+	for _, l := range c {
+		v, sign := variable(l)
+		switch assignment[v] {
+		case sign:
+			return true, 0, 0
+		case 0:
+			unassigned++
+			last = l
+		}
+	}
+	return false, unassigned, last
+}
+
+// assign makes the literal true
+func assign(assignment []int8, l int) {
+	v, sign := variable(l)
+	assignment[v] = sign
+}
+
+// variable splits the literal into its variable and its sign
+func variable(l int) (int, int8) {
+	if l < 0 {
+		return -l, -1
+	}
+	return l, 1
+}
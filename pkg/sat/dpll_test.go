@@ -0,0 +1,104 @@
+package sat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSolveDPLLAgreesWithBruteForce(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	for seed := int64(1); seed <= 300; seed++ {
+		formula := RandomFormula(symbols, 5, seed)
+		result, err := Solve(formula, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		model, err := SolveDPLL(formula, symbols)
+		if err != nil {
+			t.Fatalf("seed %d: %s: %v", seed, formula, err)
+		}
+		if (model != nil) != result.Satisfiable {
+			t.Errorf("seed %d: %s: DPLL satisfiable is %t, brute force %t", seed, formula, model != nil, result.Satisfiable)
+			continue
+		}
+		if model == nil {
+			continue
+		}
+		if len(model) != len(symbols) {
+			t.Errorf("seed %d: %s: got model %v, want a value for each symbol", seed, formula, model)
+		}
+		if res, err := Eval(formula, model); err != nil || !res {
+			t.Errorf("seed %d: %s: DPLL model %v doesn't satisfy it", seed, formula, model)
+		}
+	}
+}
+
+func TestSolveDPLLErrors(t *testing.T) {
+	if _, err := SolveDPLL("a && z", []string{"a"}); err == nil {
+		t.Errorf("expected an error for the undeclared z")
+	}
+	if _, err := SolveDPLL("a &&", []string{"a"}); err == nil {
+		t.Errorf("expected an error for a formula that doesn't parse")
+	}
+}
+
+// dpllBenchFormulas are satisfiable formulas over 24 symbols: a conjunction
+// only satisfied by the last combination the brute force search tries, a
+// chain of xors, which the CNF by distribution blows up, and a disjunction of
+// conjunctions, which it multiplies out
+var dpllBenchFormulas = map[string]string{
+	"and":   strings.Join(manySymbols(24), " && "),
+	"xor":   strings.Join(manySymbols(24), " ^ ") + " && s0 && s1",
+	"pairs": pairsFormula(manySymbols(24)),
+}
+
+// pairsFormula returns the disjunction of the conjunctions of the symbols
+// taken two at a time, all negated but the last pair
+func pairsFormula(symbols []string) string {
+	var terms []string
+	for i := 0; i+1 < len(symbols); i += 2 {
+		terms = append(terms, "!"+symbols[i]+" && !"+symbols[i+1])
+	}
+	terms[len(terms)-1] = symbols[len(symbols)-2] + " && " + symbols[len(symbols)-1]
+	return "(" + strings.Join(terms, ") || (") + ") && " + symbols[0]
+}
+
+func TestSolveDPLLLarge(t *testing.T) {
+	symbols := manySymbols(24)
+	for name, formula := range dpllBenchFormulas {
+		model, err := SolveDPLL(formula, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if res, err := Eval(formula, model); err != nil || !res {
+			t.Errorf("%s: got model %v, which doesn't satisfy it", name, model)
+		}
+	}
+}
+
+func BenchmarkSolveDPLL(b *testing.B) {
+	symbols := manySymbols(24)
+	for name, formula := range dpllBenchFormulas {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := SolveDPLL(formula, symbols); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSolveBruteForce is BenchmarkSolveDPLL with the brute force search
+func BenchmarkSolveBruteForce(b *testing.B) {
+	symbols := manySymbols(24)
+	for name, formula := range dpllBenchFormulas {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := Solve(formula, symbols); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
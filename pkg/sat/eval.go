@@ -0,0 +1,225 @@
+package sat
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// parseFormula rewrites the operators the Go parser doesn't know about and
+// parses the formula into its AST
+func parseFormula(formula string) (ast.Expr, error) {
+	expr, err := parseSyntax(formula)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the calls and turn the 0/1 constants into the boolean literals
+	return prepare(expr)
+}
+
+// parseSyntax is parseFormula without checking the calls nor rewriting the
+// constants, so the AST stays the formula as it was written, implications and
+// equivalences included
+func parseSyntax(formula string) (ast.Expr, error) {
+	rewritten, replaced := preprocess(formula)
+
+	fset := token.NewFileSet()
+	expr, err := parser.ParseExprFrom(fset, "", rewritten, 0)
+	if err != nil {
+		var list scanner.ErrorList
+		if errors.As(err, &list) && len(list) > 0 {
+			// Name the operator as it was written, not as it was replaced
+			msg := list[0].Msg
+			if op, ok := replaced[list[0].Pos.Offset]; ok {
+				msg = strings.Replace(msg, "'"+standIns[op].String()+"'", "'"+operatorString(op)+"'", 1)
+			}
+			return nil, fmt.Errorf("error parsing expression: syntax error near column %d: %s", list[0].Pos.Column, msg)
+		}
+		return nil, fmt.Errorf("error parsing expression: %v", err)
+	}
+	return regroup(expr, fset, replaced), nil
+}
+
+// parseChecked is parseSyntax, failing on the formulas parseFormula rejects,
+// for the functions showing the formula as it was written
+func parseChecked(formula string) (ast.Expr, error) {
+	expr, err := parseSyntax(formula)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := prepare(expr); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// Eval parses the formula and evaluates it on the values of its symbols
+func Eval(formula string, values map[string]bool) (bool, error) {
+	return evalBoolExpr(formula, values)
+}
+
+// evalBoolExpr parses the formula and evaluates it with Evaluate
+func evalBoolExpr(expression string, values map[string]bool) (bool, error) {
+	// Parse the boolean expression and create the AST
+	expr, err := parseFormula(expression)
+	if err != nil {
+		return false, err
+	}
+	return Evaluate(expr, values)
+}
+
+// Evaluate evaluates an expression built by hand or parsed with go/parser on
+// the values of its symbols, which are matched exactly. The functions of the
+// formulas and the 0/1 constants are understood as in Eval
+func Evaluate(expr ast.Expr, values map[string]bool) (bool, error) {
+	// The constants can't be overridden, as in the other evaluators
+	for _, constant := range []string{"true", "false"} {
+		if _, ok := values[constant]; ok {
+			return false, checkConstants([]string{constant})
+		}
+	}
+	prepared, err := prepare(expr)
+	if err != nil {
+		return false, err
+	}
+	return evalExpr(prepared, values)
+}
+
+// evalExpr evaluates an already parsed formula, so that the same AST can be
+// reused across combinations
+func evalExpr(expr ast.Expr, values map[string]bool) (bool, error) {
+	// Create a custom visitor to walk the AST and evaluate the expression
+	evalVisitor := &visitor{values: values}
+
+	// Walk the AST and evaluate the expression
+	ast.Walk(evalVisitor, expr)
+	if evalVisitor.err != nil {
+		return false, evalVisitor.err
+	}
+
+	// Return the final result
+	return evalVisitor.result, nil
+}
+
+type visitor struct {
+	values map[string]bool // Input values for identifiers
+	result bool            // Final result of the expression
+	err    error           // First error found while evaluating
+}
+
+func (v *visitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		// Operands missing from a tree built by hand can't be walked
+		v.err = errMissing
+		return nil
+	}
+
+	switch expr := node.(type) {
+	case *ast.Ident:
+		// Check if the identifier exists in the input values, falling back
+		// to the boolean literals when it doesn't
+		value, ok := v.values[expr.Name]
+		if !ok {
+			switch expr.Name {
+			case "true":
+				value = true
+			case "false":
+				value = false
+			default:
+				v.err = fmt.Errorf("identifier '%s' not found in input values", expr.Name)
+				return nil
+			}
+		}
+		v.result = value
+
+	case *ast.BasicLit:
+		// Handle the integer literals standing for the constants (e.g., 1)
+		switch {
+		case expr.Kind == token.INT && expr.Value == "1":
+			v.result = true
+		case expr.Kind == token.INT && expr.Value == "0":
+			v.result = false
+		default:
+			v.err = fmt.Errorf("unsupported literal %s, only 0 and 1 can be used as constants", expr.Value)
+			return nil
+		}
+
+	case *ast.UnaryExpr:
+		// Handle unary expressions (e.g., !c)
+		switch expr.Op {
+		case token.NOT:
+			childVisitor := &visitor{values: v.values}
+			ast.Walk(childVisitor, expr.X)
+			v.result = !childVisitor.result
+			v.err = childVisitor.err
+
+		default:
+			v.err = fmt.Errorf("unsupported unary operator: %s", expr.Op)
+			return nil
+		}
+
+	case *ast.BinaryExpr:
+		// Handle binary expressions (e.g., a && b)
+		// Stop walking at the first operand that fails to evaluate
+		leftVisitor := &visitor{values: v.values}
+		ast.Walk(leftVisitor, expr.X)
+		if v.err = leftVisitor.err; v.err != nil {
+			return nil
+		}
+		rightVisitor := &visitor{values: v.values}
+		ast.Walk(rightVisitor, expr.Y)
+		if v.err = rightVisitor.err; v.err != nil {
+			return nil
+		}
+
+		switch expr.Op {
+		case token.LAND:
+			v.result = leftVisitor.result && rightVisitor.result
+		case token.LOR:
+			v.result = leftVisitor.result || rightVisitor.result
+		case token.XOR, token.NEQ:
+			v.result = leftVisitor.result != rightVisitor.result
+		case token.EQL:
+			v.result = leftVisitor.result == rightVisitor.result
+		default:
+			v.err = fmt.Errorf("unsupported binary operator: %s", expr.Op)
+			return nil
+		}
+
+	case *ast.CallExpr:
+		// Handle calls to the builtin functions (e.g., xor(a, b))
+		call, err := resolveCall(expr)
+		if err != nil {
+			v.err = err
+			return nil
+		}
+		args := make([]bool, len(call.operands))
+		for i, arg := range call.operands {
+			argVisitor := &visitor{values: v.values}
+			ast.Walk(argVisitor, arg)
+			if v.err = argVisitor.err; v.err != nil {
+				return nil
+			}
+			args[i] = argVisitor.result
+		}
+		v.result = call.apply(args)
+
+	case *ast.ParenExpr:
+		// Handle parentheses expressions
+		childVisitor := &visitor{values: v.values}
+		ast.Walk(childVisitor, expr.X)
+		v.result = childVisitor.result
+		v.err = childVisitor.err
+
+	default:
+		v.err = fmt.Errorf("unsupported expression type: %T", node)
+		return nil
+	}
+
+	return nil // Return nil to skip children nodes
+}
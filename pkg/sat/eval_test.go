@@ -0,0 +1,320 @@
+package sat
+
+import (
+	"go/ast"
+	"go/token"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// FuzzEvalBoolExpr feeds arbitrary formulas to evalBoolExpr on random values,
+// which must either evaluate them or fail with an error, never panic. The
+// formulas it evaluates must give the same result once compiled
+func FuzzEvalBoolExpr(f *testing.F) {
+	for _, formula := range []string{
+		"a && b || !c",
+		"a -> b <-> c",
+		"a ^ b != c == d",
+		"atleast(2, a, b, c) && atmost(1, a, b) || exactly(0, c)",
+		"ite(a, b, c) && nand(a, b) || nor(a, b) || majority(a, b, c) || xnor(a, b)",
+		"1 && !0 || true && !false",
+		"a.b",
+		"a[0]",
+		"a + b",
+		"-a",
+		"f(a)",
+		"atleast(a, b)",
+		"atleast(-1, a)",
+		"\"a\"",
+		"1.5",
+		"func() {}",
+		"a &&",
+		"((a)",
+		"",
+	} {
+		f.Add(formula, int64(1))
+	}
+
+	f.Fuzz(func(t *testing.T, formula string, seed int64) {
+		rng := rand.New(rand.NewSource(seed))
+		values := make(map[string]bool)
+		for _, symbol := range []string{"a", "b", "c", "d"} {
+			values[symbol] = rng.Intn(2) == 1
+		}
+		symbols, err := ExtractSymbols(formula)
+		if len(symbols) > 64 {
+			// The compiled formula packs the combination into a uint64
+			t.Skip()
+		}
+		if err == nil {
+			for _, symbol := range symbols {
+				values[symbol] = rng.Intn(2) == 1
+			}
+		}
+
+		res, err := evalBoolExpr(formula, values)
+		if err != nil {
+			return
+		}
+
+		expr, err := parseFormula(formula)
+		if err != nil {
+			t.Fatalf("%q evaluates, but doesn't parse: %v", formula, err)
+		}
+		eval, err := compile(expr, symbols)
+		if err != nil {
+			t.Fatalf("%q evaluates, but doesn't compile: %v", formula, err)
+		}
+		var c uint64
+		for j, symbol := range symbols {
+			if values[symbol] {
+				c |= 1 << j
+			}
+		}
+		if compiled := eval(c); compiled != res {
+			t.Errorf("%q on %v: evaluates to %t, compiled to %t", formula, values, res, compiled)
+		}
+	})
+}
+
+// checkTruthTable evaluates the formula on every combination of the symbols,
+// comparing it with want, which receives the values in the order of the
+// symbols
+func checkTruthTable(t *testing.T, formula string, symbols []string, want func(v []bool) bool) {
+	t.Helper()
+	v := make([]bool, len(symbols))
+	for i := 0; i < 1<<len(symbols); i++ {
+		values := make(map[string]bool, len(symbols))
+		for j, symbol := range symbols {
+			v[j] = (i>>j)&1 == 1
+			values[symbol] = v[j]
+		}
+		got, err := Eval(formula, values)
+		if err != nil {
+			t.Fatalf("%s on %v: %v", formula, values, err)
+		}
+		if w := want(v); got != w {
+			t.Errorf("%s on %v: got %t, want %t", formula, values, got, w)
+		}
+	}
+}
+
+func TestEvalXor(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	checkTruthTable(t, "a ^ b", symbols, func(v []bool) bool { return v[0] != v[1] })
+	checkTruthTable(t, "a ^ b ^ c", symbols, func(v []bool) bool { return v[0] != v[1] != v[2] })
+	checkTruthTable(t, "(a ^ b) && c", symbols, func(v []bool) bool { return (v[0] != v[1]) && v[2] })
+	// ^ binds tighter than && and ||, as in Go
+	checkTruthTable(t, "a ^ b && c", symbols, func(v []bool) bool { return (v[0] != v[1]) && v[2] })
+	checkTruthTable(t, "c || a ^ b", symbols, func(v []bool) bool { return v[2] || (v[0] != v[1]) })
+}
+
+func TestEvalUndeclared(t *testing.T) {
+	_, err := Eval("a && b", map[string]bool{"a": true})
+	if got, want := errorString(err), "identifier 'b' not found in input values"; got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+
+	// The search reports it too instead of crashing a worker
+	if _, err := Solve("a || b", []string{"a"}); err == nil {
+		t.Errorf("expected an error solving over an undeclared symbol")
+	}
+}
+
+// benchFormula is a formula over 18 symbols for the benchmarks of the
+// evaluators
+const benchFormula = "(s0 || !s1) && (s2 ^ s3) && (s4 -> s5) && (s6 || s7 || !s8) && (s9 <-> s10) && !(s11 && s12) && (s13 || s14) && (s15 != s16) && s17"
+
+// BenchmarkEvalReparsed evaluates the formula on every combination of its 18
+// symbols, parsing it each time
+func BenchmarkEvalReparsed(b *testing.B) {
+	symbols := manySymbols(18)
+	values := make(map[string]bool, len(symbols))
+	for i := 0; i < b.N; i++ {
+		for c := 0; c < 1<<len(symbols); c++ {
+			setCombination(values, c, symbols)
+			if _, err := evalBoolExpr(benchFormula, values); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkEvalParsed is BenchmarkEvalReparsed, parsing the formula once
+func BenchmarkEvalParsed(b *testing.B) {
+	symbols := manySymbols(18)
+	values := make(map[string]bool, len(symbols))
+	expr, err := parseFormula(benchFormula)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for c := 0; c < 1<<len(symbols); c++ {
+			setCombination(values, c, symbols)
+			if _, err := evalExpr(expr, values); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestEvalExprReusesAST(t *testing.T) {
+	symbols := manySymbols(18)[:8]
+	formula := "(s0 || !s1) && (s2 ^ s3) && (s4 -> s5) || (s6 <-> s7)"
+	expr, err := parseFormula(formula)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]bool, len(symbols))
+	for c := 0; c < 1<<len(symbols); c++ {
+		setCombination(values, c, symbols)
+		got, err := evalExpr(expr, values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := Eval(formula, values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%v: the reused AST gives %t, the formula %t", values, got, want)
+		}
+	}
+}
+
+func TestEvalParseErrorColumn(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"a &&", "syntax error near column 5: expected operand"},
+		{"a ||| b", "syntax error near column 5: expected operand, found '|'"},
+		{"(a && b", "syntax error near column 8: expected ')'"},
+		{"a b", "syntax error near column 3"},
+		// The implications and equivalences don't move the columns, and are
+		// named as they were written
+		{"a -> && b", "syntax error near column 6: expected operand, found '&&'"},
+		{"a -> b)", "syntax error near column 7"},
+		{"-> b", "syntax error near column 1: expected operand, found '->'"},
+		{"a <-> <-> b", "syntax error near column 7: expected operand, found '<->'"},
+	}
+	values := map[string]bool{"a": true, "b": true}
+	for _, test := range tests {
+		_, err := Eval(test.formula, values)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want %q", test.formula, err, test.want)
+		}
+	}
+}
+
+func TestWrappedParseErrorColumn(t *testing.T) {
+	// The formulas are checked before being negated or combined, so the
+	// columns are those of the formula as written
+	symbols := []string{"a", "b"}
+	check := func(name string, err error) {
+		t.Helper()
+		if err == nil || !strings.Contains(err.Error(), "near column 5:") {
+			t.Errorf("%s: got error %v, want one near column 5", name, err)
+		}
+	}
+	_, _, err := IsTautology("a &&", symbols)
+	check("IsTautology", err)
+	_, _, err = Equivalent("a", "b ||", symbols)
+	check("Equivalent", err)
+	_, _, err = Entails("a &&", "b", symbols)
+	check("Entails", err)
+	_, _, err = Entails("a", "a ->", symbols)
+	check("Entails conclusion", err)
+}
+
+func TestEvaluate(t *testing.T) {
+	// a && !(b || c), built without parsing
+	expr := &ast.BinaryExpr{
+		X:  ast.NewIdent("a"),
+		Op: token.LAND,
+		Y: &ast.UnaryExpr{Op: token.NOT, X: &ast.ParenExpr{X: &ast.BinaryExpr{
+			X:  ast.NewIdent("b"),
+			Op: token.LOR,
+			Y:  ast.NewIdent("c"),
+		}}},
+	}
+	symbols := []string{"a", "b", "c"}
+	checkTruthTable(t, "a && !(b || c)", symbols, func(v []bool) bool {
+		res, err := Evaluate(expr, map[string]bool{"a": v[0], "b": v[1], "c": v[2]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	})
+
+	// The functions and constants of the formulas work as well
+	call := &ast.CallExpr{Fun: ast.NewIdent("atleast"), Args: []ast.Expr{
+		&ast.BasicLit{Kind: token.INT, Value: "2"}, ast.NewIdent("a"), ast.NewIdent("b"), ast.NewIdent("true"),
+	}}
+	res, err := Evaluate(call, map[string]bool{"a": false, "b": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res {
+		t.Errorf("atleast(2, a, b, true) with b true: got false")
+	}
+
+	if _, err := Evaluate(expr, map[string]bool{"a": true}); err == nil {
+		t.Errorf("expected an error for the values of b and c missing")
+	}
+}
+
+func TestUnsupportedExpressions(t *testing.T) {
+	values := map[string]bool{"a": true, "b": true}
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"a.b", "unsupported expression type: *ast.SelectorExpr"},
+		{"a[b]", "unsupported expression type: *ast.IndexExpr"},
+		{"a[1:2]", "unsupported expression type: *ast.SliceExpr"},
+		{"a.(b)", "unsupported expression type: *ast.TypeAssertExpr"},
+		{"*a", "unsupported expression type: *ast.StarExpr"},
+		{"func() {}", "unsupported expression type: *ast.FuncLit"},
+		{"[]int{}", "unsupported expression type: *ast.CompositeLit"},
+		{"-a", "unsupported unary operator: -"},
+		{"&a", "unsupported unary operator: &"},
+		{"a + b", "unsupported binary operator: +"},
+		{"a < b", "unsupported binary operator: <"},
+		{"a || f(b)", "unknown function 'f'"},
+	}
+	for _, tt := range tests {
+		_, err := Eval(tt.formula, values)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Eval(%q): got error %v, want %q", tt.formula, err, tt.want)
+		}
+		if _, err := Solve(tt.formula, []string{"a", "b"}); err == nil {
+			t.Errorf("Solve(%q): expected an error", tt.formula)
+		}
+	}
+
+	// Evaluate reports the same errors on nodes built by hand
+	exprs := []struct {
+		expr ast.Expr
+		want string
+	}{
+		{&ast.SelectorExpr{X: ast.NewIdent("a"), Sel: ast.NewIdent("b")}, "unsupported expression type: *ast.SelectorExpr"},
+		{&ast.StarExpr{X: ast.NewIdent("a")}, "unsupported expression type: *ast.StarExpr"},
+		{&ast.BinaryExpr{X: ast.NewIdent("a"), Op: token.ADD, Y: ast.NewIdent("b")}, "unsupported binary operator: +"},
+		{&ast.UnaryExpr{Op: token.XOR, X: ast.NewIdent("a")}, "unsupported unary operator: ^"},
+		{nil, "missing expression"},
+		{(*ast.Ident)(nil), "missing expression"},
+		{&ast.BinaryExpr{X: ast.NewIdent("a"), Op: token.LAND, Y: (*ast.Ident)(nil)}, "missing expression"},
+		{&ast.UnaryExpr{Op: token.NOT, X: (*ast.BinaryExpr)(nil)}, "missing expression"},
+		{&ast.ParenExpr{X: (*ast.BasicLit)(nil)}, "missing expression"},
+		{&ast.CallExpr{Fun: ast.NewIdent("xor"), Args: []ast.Expr{ast.NewIdent("a"), (*ast.CallExpr)(nil)}}, "missing expression"},
+	}
+	for _, tt := range exprs {
+		_, err := Evaluate(tt.expr, values)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Evaluate(%T): got error %v, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
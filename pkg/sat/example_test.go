@@ -0,0 +1,66 @@
+package sat_test
+
+import (
+	"fmt"
+
+	"psc-project/pkg/sat"
+)
+
+func ExampleSolve() {
+	result, err := sat.Solve("a && !b", []string{"a", "b"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(result.Satisfiable, result.Assignment)
+	// Output: true map[a:true b:false]
+}
+
+func ExampleSolve_unsatisfiable() {
+	result, err := sat.Solve("a && !a", []string{"a"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(result.Satisfiable, result.Assignment)
+	// Output: false map[]
+}
+
+func ExampleEval() {
+	res, err := sat.Eval("a -> b", map[string]bool{"a": true, "b": false})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(res)
+	// Output: false
+}
+
+func ExampleExtractSymbols() {
+	symbols, err := sat.ExtractSymbols("c || (b && !a) || true")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(symbols)
+	// Output: [a b c]
+}
+
+func ExampleIsTautology() {
+	valid, _, err := sat.IsTautology("a || !a", []string{"a"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(valid)
+
+	valid, counter, err := sat.IsTautology("a || b", []string{"a", "b"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(valid, counter)
+	// Output:
+	// true
+	// false map[a:false b:false]
+}
@@ -0,0 +1,200 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+// errMissing is the error for an operand missing from a tree built by hand
+var errMissing = fmt.Errorf("missing expression")
+
+// prepare turns the parsed formula into the expression the evaluators walk:
+// the operators and the calls are checked, the implications and equivalences
+// are rewritten with the Go operators, and the integer literals 0 and 1
+// become false and true.
+// The count of a cardinality constraint stays an integer, so that the
+// constraint is evaluated by counting its true operands instead of being
+// expanded into a tree exponentially larger than the formula
+func prepare(node ast.Expr) (ast.Expr, error) {
+	// Operands missing from a tree built by hand can't be walked, whether
+	// they are left out or typed nil pointers
+	if node == nil {
+		return nil, errMissing
+	}
+	switch expr := node.(type) {
+	case *ast.ParenExpr:
+		if expr == nil {
+			return nil, errMissing
+		}
+		x, err := prepare(expr.X)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ParenExpr{Lparen: expr.Lparen, X: x, Rparen: expr.Rparen}, nil
+
+	case *ast.UnaryExpr:
+		if expr == nil {
+			return nil, errMissing
+		}
+		if expr.Op != token.NOT {
+			return nil, fmt.Errorf("unsupported unary operator: %s", expr.Op)
+		}
+		x, err := prepare(expr.X)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.UnaryExpr{OpPos: expr.OpPos, Op: expr.Op, X: x}, nil
+
+	case *ast.BinaryExpr:
+		if expr == nil {
+			return nil, errMissing
+		}
+		switch expr.Op {
+		case token.LAND, token.LOR, token.XOR, token.NEQ, token.EQL, opImplies, opIff:
+		default:
+			return nil, fmt.Errorf("unsupported binary operator: %s", expr.Op)
+		}
+		x, err := prepare(expr.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := prepare(expr.Y)
+		if err != nil {
+			return nil, err
+		}
+		switch expr.Op {
+		case opImplies:
+			// An implication holds when the premise is false or the
+			// conclusion true
+			return &ast.ParenExpr{X: &ast.BinaryExpr{X: &ast.UnaryExpr{OpPos: expr.OpPos, Op: token.NOT, X: &ast.ParenExpr{X: x}}, OpPos: expr.OpPos, Op: token.LOR, Y: &ast.ParenExpr{X: y}}}, nil
+		case opIff:
+			return &ast.ParenExpr{X: &ast.BinaryExpr{X: &ast.ParenExpr{X: x}, OpPos: expr.OpPos, Op: token.EQL, Y: &ast.ParenExpr{X: y}}}, nil
+		}
+		return &ast.BinaryExpr{X: x, OpPos: expr.OpPos, Op: expr.Op, Y: y}, nil
+
+	case *ast.CallExpr:
+		if expr == nil {
+			return nil, errMissing
+		}
+		call, err := resolveCall(expr)
+		if err != nil {
+			return nil, err
+		}
+		// The count comes first and is kept as it is
+		counted := len(expr.Args) - len(call.operands)
+		args := append([]ast.Expr(nil), expr.Args[:counted]...)
+		for _, arg := range call.operands {
+			arg, err := prepare(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		return &ast.CallExpr{Fun: expr.Fun, Lparen: expr.Lparen, Args: args, Rparen: expr.Rparen}, nil
+
+	case *ast.BasicLit:
+		if expr == nil {
+			return nil, errMissing
+		}
+		// 1 and 0 stand for the boolean literals
+		if expr.Kind == token.INT {
+			switch expr.Value {
+			case "1":
+				return constant(true), nil
+			case "0":
+				return constant(false), nil
+			}
+		}
+		return nil, fmt.Errorf("unsupported literal %s, only 0 and 1 can be used as constants", expr.Value)
+
+	case *ast.Ident:
+		if expr == nil {
+			return nil, errMissing
+		}
+		return node, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported expression type: %T", node)
+	}
+}
+
+// cardinality splits the arguments of a cardinality constraint into its
+// count, which must be an integer literal, and its operands. The call was
+// checked to have at least the count
+func cardinality(name string, args []ast.Expr) (int, []ast.Expr, error) {
+	lit, ok := args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.INT {
+		return 0, nil, fmt.Errorf("%s: the count must be an integer literal", name)
+	}
+	k, err := strconv.Atoi(lit.Value)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: invalid count %s", name, lit.Value)
+	}
+	return k, args[1:], nil
+}
+
+// atLeast builds the expression that is true when at least k of the operands
+// are. It follows the recurrence "the first operand and k-1 of the others, or
+// k of the others", sharing the repeated subexpressions so that it only takes
+// k times the number of operands nodes. As a tree it would be as large as a
+// binomial coefficient of the operands, so its walks must be memoized on the
+// nodes
+func atLeast(k int, operands []ast.Expr) ast.Expr {
+	memo := make(map[[2]int]ast.Expr)
+
+	var build func(i, k int) ast.Expr
+	build = func(i, k int) ast.Expr {
+		switch {
+		case k <= 0:
+			return constant(true)
+		case len(operands)-i < k:
+			return constant(false)
+		}
+		if expr, ok := memo[[2]int{i, k}]; ok {
+			return expr
+		}
+		expr := disjoin(conjoin(operands[i], build(i+1, k-1)), build(i+1, k))
+		memo[[2]int{i, k}] = expr
+		return expr
+	}
+
+	return build(0, k)
+}
+
+// conjoin returns x && y, folding the boolean literals
+func conjoin(x, y ast.Expr) ast.Expr {
+	return combine(x, token.LAND, y)
+}
+
+// disjoin returns x || y, folding the boolean literals
+func disjoin(x, y ast.Expr) ast.Expr {
+	return combine(x, token.LOR, y)
+}
+
+func combine(x ast.Expr, op token.Token, y ast.Expr) ast.Expr {
+	// False dominates a conjunction and is its identity in a disjunction,
+	// and the other way around for true
+	dominant := op == token.LOR
+	xValue, xConst := isConstant(x)
+	yValue, yConst := isConstant(y)
+	switch {
+	case xConst && xValue == dominant, yConst && yValue == dominant:
+		return constant(dominant)
+	case xConst:
+		return y
+	case yConst:
+		return x
+	}
+	return &ast.BinaryExpr{X: group(x), Op: op, Y: group(y)}
+}
+
+// group wraps binary expressions in parentheses, so that they can be used as
+// operands whatever their precedence
+func group(expr ast.Expr) ast.Expr {
+	if _, ok := expr.(*ast.BinaryExpr); ok {
+		return &ast.ParenExpr{X: expr}
+	}
+	return expr
+}
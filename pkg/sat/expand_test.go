@@ -0,0 +1,253 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+	"testing"
+)
+
+// binomial returns the number of ways of choosing k of n things
+func binomial(n, k int) int {
+	if k < 0 || k > n {
+		return 0
+	}
+	c := 1
+	for i := 0; i < k; i++ {
+		c = c * (n - i) / (i + 1)
+	}
+	return c
+}
+
+func TestCardinalityCounts(t *testing.T) {
+	for n := 1; n <= 6; n++ {
+		symbols := manySymbols(n)
+		operands := strings.Join(symbols, ", ")
+		for k := 0; k <= n+1; k++ {
+			atLeast, atMost := 0, 0
+			for j := 0; j <= n; j++ {
+				if j >= k {
+					atLeast += binomial(n, j)
+				}
+				if j <= k {
+					atMost += binomial(n, j)
+				}
+			}
+			want := map[string]int{
+				fmt.Sprintf("atleast(%d, %s)", k, operands): atLeast,
+				fmt.Sprintf("atmost(%d, %s)", k, operands):  atMost,
+				fmt.Sprintf("exactly(%d, %s)", k, operands): binomial(n, k),
+			}
+			for formula, count := range want {
+				got, err := CountSolutions(formula, symbols)
+				if err != nil {
+					t.Fatalf("%s: %v", formula, err)
+				}
+				if got != count {
+					t.Errorf("%s: got %d solutions, want %d", formula, got, count)
+				}
+			}
+		}
+	}
+}
+
+func TestCardinalityErrors(t *testing.T) {
+	for _, formula := range []string{
+		"atleast(a, b)",
+		"atleast(-1, a)",
+		"atmost()",
+		"exactly(1.5, a)",
+	} {
+		if _, err := Eval(formula, map[string]bool{"a": true, "b": true}); err == nil {
+			t.Errorf("%s: expected an error", formula)
+		}
+	}
+}
+
+func TestRewritingsUnsupportedExpressions(t *testing.T) {
+	// The rewritings reject what the evaluators reject, even where they
+	// could leave it as it is
+	rewritings := map[string]func(formula string) error{
+		"Simplify":  func(f string) error { _, err := Simplify(f); return err },
+		"Normalize": func(f string) error { _, err := Normalize(f); return err },
+		"Format":    func(f string) error { _, err := Format(f); return err },
+		"Metrics":   func(f string) error { _, err := Metrics(f); return err },
+		"ToDOT":     func(f string) error { _, err := ToDOT(f); return err },
+		"PrintTree": func(f string) error { _, err := PrintTree(f); return err },
+		"ExtractSymbols": func(f string) error {
+			_, err := ExtractSymbols(f)
+			return err
+		},
+		"Eval": func(f string) error {
+			_, err := Eval(f, map[string]bool{"a": true, "b": true})
+			return err
+		},
+	}
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"a.b && true", "unsupported expression type: *ast.SelectorExpr"},
+		{"x[0] || a", "unsupported expression type: *ast.IndexExpr"},
+		{"-a", "unsupported unary operator: -"},
+		{"!(^a && b)", "unsupported unary operator: ^"},
+		{"a + b", "unsupported binary operator: +"},
+		{"a * b", "unsupported binary operator: *"},
+		{"a % a", "unsupported binary operator: %"},
+		{"a || (a << b)", "unsupported binary operator: <<"},
+	}
+	for name, rewrite := range rewritings {
+		for _, tt := range tests {
+			if err := rewrite(tt.formula); err == nil || err.Error() != tt.want {
+				t.Errorf("%s(%q): got error %v, want %q", name, tt.formula, err, tt.want)
+			}
+		}
+	}
+}
+
+// BenchmarkAtLeastCompile parses and compiles a cardinality constraint whose
+// expansion, walked as a tree, would be binomial in its operands
+func BenchmarkAtLeastCompile(b *testing.B) {
+	symbols := manySymbols(16)
+	formula := "atleast(8, " + strings.Join(symbols, ", ") + ")"
+	for i := 0; i < b.N; i++ {
+		expr, err := parseFormula(formula)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := compile(expr, symbols); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestExactly(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	nTrue := func(v ...bool) int {
+		n := 0
+		for _, value := range v {
+			if value {
+				n++
+			}
+		}
+		return n
+	}
+	checkTruthTable(t, "exactly(2, a, b, c, d)", symbols, func(v []bool) bool { return nTrue(v...) == 2 })
+	checkTruthTable(t, "exactly(1, a && b, !c, d)", symbols, func(v []bool) bool { return nTrue(v[0] && v[1], !v[2], v[3]) == 1 })
+	checkTruthTable(t, "!exactly(0, a, b) || c", symbols, func(v []bool) bool { return nTrue(v[0], v[1]) != 0 || v[2] })
+
+	// The expansion, written back as a formula, means the same
+	for k := 0; k <= 4; k++ {
+		formula := fmt.Sprintf("exactly(%d, a, b, c, d)", k)
+		expr, err := parseFormula(formula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lowered, err := lowerCall(expr.(*ast.CallExpr), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expanded := formatExpr(lowered)
+		if strings.Contains(expanded, "exactly") {
+			t.Errorf("%s: got %q, want it expanded", formula, expanded)
+		}
+		want := k
+		checkTruthTable(t, expanded, symbols, func(v []bool) bool { return nTrue(v...) == want })
+
+		equivalent, counter, err := Equivalent(formula, fmt.Sprintf("atleast(%d, a, b, c, d) && atmost(%d, a, b, c, d)", k, k), symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equivalent {
+			t.Errorf("%s: differs from atleast and atmost on %v", formula, counter)
+		}
+	}
+}
+
+func TestCardinalityLarge(t *testing.T) {
+	// Expanded and walked as a tree, these would take longer than the test
+	// could ever run
+	symbols := manySymbols(40)
+	operands := strings.Join(symbols, ", ")
+	formula := "atleast(20, " + operands + ")"
+
+	got, err := ExtractSymbols(formula)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(symbols) {
+		t.Errorf("got %d symbols, want %d", len(got), len(symbols))
+	}
+	if normalized, err := Normalize(formula); err != nil || strings.Count(normalized, ",") != len(symbols) {
+		t.Errorf("got %q, %v, want the call kept as it is", normalized, err)
+	}
+	if counts, err := CommonSubexpressions(formula); err != nil || counts[formula] != 1 || len(counts) != len(symbols)+1 {
+		t.Errorf("got %v, %v, want the call and its operands once", counts, err)
+	}
+
+	// The diagrams memoize the expansion on its shared nodes
+	for _, tt := range []struct {
+		formula string
+		count   func(j int) bool
+	}{
+		{formula, func(j int) bool { return j >= 20 }},
+		{"atmost(3, " + operands + ")", func(j int) bool { return j <= 3 }},
+		{"exactly(38, " + operands + ")", func(j int) bool { return j == 38 }},
+	} {
+		bdd, err := BuildBDD(tt.formula, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 0
+		for j := 0; j <= len(symbols); j++ {
+			if tt.count(j) {
+				want += binomial(len(symbols), j)
+			}
+		}
+		if got := bdd.SatCount(); got != want {
+			t.Errorf("%.12s...: got %d solutions, want %d", tt.formula, got, want)
+		}
+	}
+
+	// The search counts the true operands on each combination. In sorted
+	// order s9 comes last, so it's the least significant
+	result, err := Solve("exactly(1, "+operands+") && !s0", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for symbol, value := range result.Assignment {
+		if value != (symbol == "s9") {
+			t.Errorf("got %v, want only s9 true", result.Assignment)
+			break
+		}
+	}
+}
+
+func TestCardinalityNNF(t *testing.T) {
+	// The memoized conversion of the shared nodes must still mean the same
+	symbols := manySymbols(6)
+	for _, formula := range []string{
+		"atleast(3, s0, s1, s2, s3, s4, s5)",
+		"!atmost(2, s0, s1, s2, s3, s4, s5)",
+		"exactly(2, s0, s1 && s2, s3, !s4, s5) || s1",
+	} {
+		nnf, err := ToNNF(formula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, witness, err := Equivalent(formula, nnf, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("%s: %s disagrees on %v", formula, nnf, witness)
+		}
+		cnf, err := ToCNF(formula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, witness, err := Equivalent(formula, cnf, symbols); err != nil || !ok {
+			t.Errorf("%s: %s disagrees on %v, %v", formula, cnf, witness, err)
+		}
+	}
+}
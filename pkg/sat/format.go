@@ -0,0 +1,136 @@
+package sat
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"strings"
+)
+
+// Format renders the formula with the fewest parentheses its operators need,
+// as the other functions returning a formula do. Grouping a chain of the same
+// operator differently doesn't change its result, so its parentheses are
+// dropped as well
+func Format(formula string) (string, error) {
+	expr, err := parseChecked(formula)
+	if err != nil {
+		return "", err
+	}
+	return formatExpr(expr), nil
+}
+
+// formatExpr renders the expression back into a formula, with only the
+// needed parentheses
+func formatExpr(expr ast.Expr) string {
+	return printExpr(minimalParens(expr))
+}
+
+// printExpr prints the expression with go/format. Go has no implication nor
+// equivalence, so those at the top are joined by hand, and those nested in
+// the expression are printed on their own in place of placeholders
+func printExpr(expr ast.Expr) string {
+	if arrow, ok := expr.(*ast.BinaryExpr); ok && (arrow.Op == opImplies || arrow.Op == opIff) {
+		return printExpr(arrow.X) + " " + operatorString(arrow.Op) + " " + printExpr(arrow.Y)
+	}
+
+	var nested []string
+	expr = replaceArrows(expr, &nested)
+	var b bytes.Buffer
+	// Formatting a well formed expression to a buffer can't fail
+	_ = format.Node(&b, token.NewFileSet(), expr)
+	text := b.String()
+	for i, arrow := range nested {
+		text = strings.Replace(text, placeholder(i), arrow, 1)
+	}
+	return text
+}
+
+// replaceArrows returns the expression with the implications and equivalences
+// replaced by placeholders, appending their text to the nested ones
+func replaceArrows(node ast.Expr, nested *[]string) ast.Expr {
+	switch expr := node.(type) {
+	case *ast.ParenExpr:
+		return &ast.ParenExpr{X: replaceArrows(expr.X, nested)}
+	case *ast.UnaryExpr:
+		return &ast.UnaryExpr{Op: expr.Op, X: replaceArrows(expr.X, nested)}
+	case *ast.BinaryExpr:
+		if expr.Op == opImplies || expr.Op == opIff {
+			*nested = append(*nested, printExpr(expr))
+			return ast.NewIdent(placeholder(len(*nested) - 1))
+		}
+		return &ast.BinaryExpr{X: replaceArrows(expr.X, nested), Op: expr.Op, Y: replaceArrows(expr.Y, nested)}
+	case *ast.CallExpr:
+		args := make([]ast.Expr, len(expr.Args))
+		for i, arg := range expr.Args {
+			args[i] = replaceArrows(arg, nested)
+		}
+		return &ast.CallExpr{Fun: expr.Fun, Args: args}
+	default:
+		return expr
+	}
+}
+
+// placeholder returns the name standing for the nested operator of the index,
+// which can't be the name of a symbol
+func placeholder(i int) string {
+	return fmt.Sprintf("\x00%d\x00", i)
+}
+
+// minimalParens returns the expression rebuilt with parentheses only around
+// the operands binding looser than their operator. Nodes it doesn't know are
+// kept as they are
+func minimalParens(node ast.Expr) ast.Expr {
+	switch expr := node.(type) {
+	case *ast.ParenExpr:
+		return minimalParens(expr.X)
+
+	case *ast.UnaryExpr:
+		return &ast.UnaryExpr{Op: expr.Op, X: group(minimalParens(expr.X))}
+
+	case *ast.BinaryExpr:
+		// Regrouping a chain of an associative operator to the left, as the
+		// parser does, spares the parentheses of its operands on the right
+		operands := []ast.Expr{expr.X, expr.Y}
+		if associative(expr.Op) {
+			operands = flatten(expr, expr.Op)
+		}
+		result := minimalParens(operands[0])
+		// The left operand only needs them if it binds looser, and the right
+		// one if it doesn't bind tighter, the other way around for the
+		// operators grouping to the right
+		prec := precedence(expr.Op)
+		if inner, ok := result.(*ast.BinaryExpr); ok && (precedence(inner.Op) < prec || precedence(inner.Op) == prec && rightAssociative(expr.Op)) {
+			result = &ast.ParenExpr{X: result}
+		}
+		for _, operand := range operands[1:] {
+			y := minimalParens(operand)
+			if inner, ok := y.(*ast.BinaryExpr); ok && (precedence(inner.Op) < prec || precedence(inner.Op) == prec && !rightAssociative(expr.Op)) {
+				y = &ast.ParenExpr{X: y}
+			}
+			result = &ast.BinaryExpr{X: result, Op: expr.Op, Y: y}
+		}
+		return result
+
+	case *ast.CallExpr:
+		args := make([]ast.Expr, len(expr.Args))
+		for i, arg := range expr.Args {
+			args[i] = minimalParens(arg)
+		}
+		return &ast.CallExpr{Fun: expr.Fun, Args: args}
+
+	default:
+		return expr
+	}
+}
+
+// associative reports whether the chains of the operator have the same
+// result however they are grouped
+func associative(op token.Token) bool {
+	switch op {
+	case token.LAND, token.LOR, token.XOR, token.NEQ, token.EQL, opIff:
+		return true
+	}
+	return false
+}
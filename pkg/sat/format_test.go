@@ -0,0 +1,82 @@
+package sat
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		// && binds tighter than ||, so grouping it needs no parentheses
+		{"(a && b) || c", "a && b || c"},
+		{"a || (b && c)", "a || b && c"},
+		{"(a || b) && c", "(a || b) && c"},
+		{"a && (b || c)", "a && (b || c)"},
+		// Chains of the same operator can be regrouped
+		{"a && (b && c)", "a && b && c"},
+		{"a == (b == c)", "a == b == c"},
+		// The comparisons bind tighter than && but looser than ^
+		{"(a == b) && c", "a == b && c"},
+		{"a == (b && c)", "a == (b && c)"},
+		{"(a != b) ^ c", "(a != b) ^ c"},
+		{"(a ^ b) != c", "a^b != c"},
+		{"(a || b) == c", "(a || b) == c"},
+		// Negations keep the parentheses of the operators they apply to
+		{"!(a && b)", "!(a && b)"},
+		{"!(a)", "!a"},
+		{"(!a) || b", "!a || b"},
+		{"((a))", "a"},
+		{"xor((a && b), (c))", "xor(a && b, c)"},
+		// The formula is formatted as written, not rewritten
+		{"(a) -> (b)", "a -> b"},
+		{"a -> (b -> c)", "a -> b -> c"},
+		{"(a -> b) -> c", "(a -> b) -> c"},
+		{"(a <-> b) <-> c", "a <-> b <-> c"},
+		{"(a || b) -> !(c <-> a)", "a || b -> !(c <-> a)"},
+		{"atleast(2, (a), b, c)", "atleast(2, a, b, c)"},
+		{"a && 1", "a && 1"},
+	}
+	for _, test := range tests {
+		got, err := Format(test.formula)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.formula, got, test.want)
+		}
+		equivalent, counter, err := Equivalent(test.formula, got, []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equivalent {
+			t.Errorf("%s: formatted to %q, which differs on %v", test.formula, got, counter)
+		}
+	}
+}
+
+func TestFormatRandom(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	for seed := int64(1); seed <= 200; seed++ {
+		// The printed random formulas are already formatted, so add some
+		// parentheses to drop
+		formula := "((" + RandomFormula(symbols, 5, seed) + "))"
+		got, err := Format(formula)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		again, err := Format(got)
+		if err != nil {
+			t.Fatalf("%s: %v", got, err)
+		}
+		if again != got {
+			t.Errorf("%s: formatted to %q, then to %q", formula, got, again)
+		}
+		equivalent, counter, err := Equivalent(formula, got, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equivalent {
+			t.Errorf("%s: formatted to %q, which differs on %v", formula, got, counter)
+		}
+	}
+}
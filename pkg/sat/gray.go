@@ -0,0 +1,209 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math/bits"
+)
+
+// grayCode returns the i-th combination in Gray code order, where each
+// combination differs from the previous one in a single bit
+func grayCode(i int) uint64 {
+	return uint64(i ^ i>>1)
+}
+
+// forEachGray evaluates the formula, written in the notation of the options,
+// on every combination of the symbols in Gray code order, calling fn with
+// each combination and its result until it returns false. Since a single
+// symbol changes at each step, only the nodes depending on it are evaluated
+// again. It gives up with the context error once the context is done
+func forEachGray(ctx context.Context, formula string, symbols []string, opts Options, fn func(c uint64, res bool) bool) error {
+	expr, err := opts.parseFormula(formula)
+	if err != nil {
+		return err
+	}
+	inc, err := newIncremental(expr, symbols)
+	if err != nil {
+		return err
+	}
+
+	nCombinations, err := countCombinations(symbols)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < nCombinations; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if i > 0 {
+			// The bit flipping between i-1 and i is the lowest set bit of i
+			inc.flip(bits.TrailingZeros(uint(i)))
+		}
+		if !fn(grayCode(i), inc.result()) {
+			break
+		}
+	}
+	return nil
+}
+
+// incremental is an evaluator keeping the value of every node of the
+// formula, so that changing a symbol only updates the nodes above it
+type incremental struct {
+	nodes  []incNode
+	leaves [][]int // Nodes reading each symbol
+	root   int
+}
+
+// incNode is a node of the formula in an incremental evaluator
+type incNode struct {
+	op       token.Token // token.IDENT for the leaves, token.FUNC for calls
+	call     boundCall
+	children []int
+	parent   int // -1 for the root
+	value    bool
+}
+
+// newIncremental builds the incremental evaluator of the formula, starting
+// from the combination with every symbol false
+func newIncremental(expr ast.Expr, symbols []string) (*incremental, error) {
+	if err := checkConstants(symbols); err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(symbols))
+	for j, symbol := range symbols {
+		index[symbol] = j
+	}
+
+	inc := &incremental{leaves: make([][]int, len(symbols))}
+	root, err := inc.add(expr, index)
+	if err != nil {
+		return nil, err
+	}
+	inc.root = root
+	inc.nodes[root].parent = -1
+	return inc, nil
+}
+
+// add appends the nodes of the expression, children first, and returns the
+// index of its root
+func (inc *incremental) add(node ast.Expr, index map[string]int) (int, error) {
+	var n incNode
+	var children []ast.Expr
+	switch expr := node.(type) {
+	case *ast.Ident:
+		n.op = token.IDENT
+		if j, ok := index[expr.Name]; ok {
+			inc.leaves[j] = append(inc.leaves[j], len(inc.nodes))
+			break
+		}
+		switch expr.Name {
+		case "true":
+			n.value = true
+		case "false":
+		default:
+			return 0, fmt.Errorf("identifier '%s' not found in input values", expr.Name)
+		}
+
+	case *ast.UnaryExpr:
+		if expr.Op != token.NOT {
+			return 0, fmt.Errorf("unsupported unary operator: %s", expr.Op)
+		}
+		n.op, children = expr.Op, []ast.Expr{expr.X}
+
+	case *ast.BinaryExpr:
+		switch expr.Op {
+		case token.LAND, token.LOR, token.XOR, token.NEQ, token.EQL:
+		default:
+			return 0, fmt.Errorf("unsupported binary operator: %s", expr.Op)
+		}
+		n.op, children = expr.Op, []ast.Expr{expr.X, expr.Y}
+
+	case *ast.CallExpr:
+		call, err := resolveCall(expr)
+		if err != nil {
+			return 0, err
+		}
+		n.op, n.call, children = token.FUNC, call, call.operands
+
+	case *ast.ParenExpr:
+		return inc.add(expr.X, index)
+
+	default:
+		return 0, fmt.Errorf("unsupported expression type: %T", node)
+	}
+
+	for _, child := range children {
+		i, err := inc.add(child, index)
+		if err != nil {
+			return 0, err
+		}
+		n.children = append(n.children, i)
+	}
+
+	i := len(inc.nodes)
+	for _, child := range n.children {
+		inc.nodes[child].parent = i
+	}
+	inc.nodes = append(inc.nodes, n)
+	inc.nodes[i].value = inc.evalNode(i)
+	return i, nil
+}
+
+// evalNode computes the value of the node from the values of its children
+func (inc *incremental) evalNode(i int) bool {
+	n := &inc.nodes[i]
+	child := func(k int) bool { return inc.nodes[n.children[k]].value }
+	switch n.op {
+	case token.NOT:
+		return !child(0)
+	case token.LAND:
+		return child(0) && child(1)
+	case token.LOR:
+		return child(0) || child(1)
+	case token.XOR, token.NEQ:
+		return child(0) != child(1)
+	case token.EQL:
+		return child(0) == child(1)
+	case token.FUNC:
+		// The cardinality constraints only need to count the true operands
+		if count := n.call.f.count; count != nil {
+			nTrue := 0
+			for k := range n.children {
+				if child(k) {
+					nTrue++
+				}
+			}
+			return count(n.call.k, nTrue)
+		}
+		values := make([]bool, len(n.children))
+		for k := range n.children {
+			values[k] = child(k)
+		}
+		return n.call.apply(values)
+	default:
+		return n.value
+	}
+}
+
+// flip negates the symbol at index j, updating the nodes above its leaves
+// until their value stops changing
+func (inc *incremental) flip(j int) {
+	for _, leaf := range inc.leaves[j] {
+		inc.nodes[leaf].value = !inc.nodes[leaf].value
+		for i := inc.nodes[leaf].parent; i >= 0; i = inc.nodes[i].parent {
+			value := inc.evalNode(i)
+			if value == inc.nodes[i].value {
+				break
+			}
+			inc.nodes[i].value = value
+		}
+	}
+}
+
+// result returns the value of the formula on the current combination
+func (inc *incremental) result() bool {
+	return inc.nodes[inc.root].value
+}
@@ -0,0 +1,91 @@
+package sat
+
+import (
+	"context"
+	"math/bits"
+	"testing"
+)
+
+func TestGrayCode(t *testing.T) {
+	for n := 0; n <= 10; n++ {
+		seen := make([]bool, 1<<n)
+		for i := 0; i < 1<<n; i++ {
+			c := grayCode(i)
+			if c >= uint64(len(seen)) || seen[c] {
+				t.Fatalf("n=%d: combination %d visited twice or out of range at step %d", n, c, i)
+			}
+			seen[c] = true
+			if i > 0 {
+				if diff := bits.OnesCount64(c ^ grayCode(i-1)); diff != 1 {
+					t.Errorf("n=%d: steps %d and %d differ in %d bits", n, i-1, i, diff)
+				}
+			}
+		}
+	}
+}
+
+func TestForEachGray(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	for seed := int64(1); seed <= 100; seed++ {
+		formula := RandomFormula(symbols, 5, seed)
+
+		visited := make(map[uint64]bool)
+		gray := make(map[uint64]bool)
+		err := forEachGray(context.Background(), formula, symbols, Options{}, func(c uint64, res bool) bool {
+			if visited[c] {
+				t.Errorf("%s: combination %d visited twice", formula, c)
+			}
+			visited[c] = true
+			if res {
+				gray[c] = true
+			}
+			return true
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		if len(visited) != 1<<len(symbols) {
+			t.Errorf("%s: visited %d combinations, want %d", formula, len(visited), 1<<len(symbols))
+		}
+
+		// The same combinations satisfy it as in ascending order
+		i := uint64(0)
+		err = forEachCombination(context.Background(), formula, symbols, Options{}, func(_ map[string]bool, res bool) bool {
+			if res != gray[i] {
+				t.Errorf("%s: combination %d gives %t in ascending order, %t in Gray code order", formula, i, res, gray[i])
+			}
+			i++
+			return true
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+	}
+}
+
+func TestForEachGrayStops(t *testing.T) {
+	calls := 0
+	err := forEachGray(context.Background(), "a || b", []string{"a", "b", "c"}, Options{}, func(_ uint64, res bool) bool {
+		calls++
+		return !res
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 000, then 001 satisfies it
+	if calls != 2 {
+		t.Errorf("got %d calls, want 2", calls)
+	}
+}
+
+// BenchmarkForEachGray is BenchmarkEvalParsed in Gray code order, evaluating
+// again only the nodes above the symbol that changed
+func BenchmarkForEachGray(b *testing.B) {
+	symbols := manySymbols(18)
+	for i := 0; i < b.N; i++ {
+		err := forEachGray(context.Background(), benchFormula, symbols, Options{}, func(_ uint64, _ bool) bool { return true })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
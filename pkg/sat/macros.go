@@ -0,0 +1,140 @@
+package sat
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// ExpandMacros replaces the identifiers of the formula naming one of the
+// macros with its definition in parentheses, expanding the macros it uses in
+// turn. The names of the functions being called are left alone, and a macro
+// can't be named like a builtin function. A definition relying on itself,
+// directly or not, is an error. The formula is returned as it is if it uses
+// no macro. Otherwise it is parsed before being expanded, so that its syntax
+// errors point into the formula as written, and those of a definition into
+// the definition
+func ExpandMacros(formula string, macros map[string]string) (string, error) {
+	return expandNotation(formula, macros, Options{})
+}
+
+// ExpandMacrosRPN is ExpandMacros for the formulas and the definitions in
+// postfix notation, as FromRPN reads them. The definitions are spliced in
+// as they are, since the postfix notation needs no parentheses
+func ExpandMacrosRPN(formula string, macros map[string]string) (string, error) {
+	return expandNotation(formula, macros, Options{Postfix: true})
+}
+
+// expandNotation is ExpandMacros in the notation of the options
+func expandNotation(formula string, macros map[string]string, opts Options) (string, error) {
+	names := make([]string, 0, len(macros))
+	for name := range macros {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if IsBuiltin(name) {
+			return "", fmt.Errorf("macro '%s' is named like a builtin function", name)
+		}
+	}
+	return expandMacros(formula, macros, nil, opts)
+}
+
+// isMacro reports whether the i-th token uses one of the macros, rather than
+// naming the function of a call
+func isMacro(tokens []string, i int, macros map[string]string) bool {
+	if _, ok := macros[tokens[i]]; !ok || !token.IsIdentifier(tokens[i]) {
+		return false
+	}
+	return i+1 == len(tokens) || tokens[i+1] != "("
+}
+
+// expandMacros is expandNotation within the definitions of the macros being
+// expanded, from the outermost one
+func expandMacros(formula string, macros map[string]string, expanding []string, opts Options) (string, error) {
+	tokens := tokenize(formula)
+	uses := false
+	for i := range tokens {
+		if isMacro(tokens, i, macros) {
+			uses = true
+			break
+		}
+	}
+
+	// The definitions are always checked, the formula only when it is
+	// about to be expanded
+	if n := len(expanding); n > 0 {
+		if _, err := opts.parseSyntax(formula); err != nil {
+			return "", fmt.Errorf("macro '%s': %w", expanding[n-1], err)
+		}
+	} else if uses {
+		if _, err := opts.parseSyntax(formula); err != nil {
+			return "", err
+		}
+	}
+	if !uses {
+		return formula, nil
+	}
+
+	for i, tok := range tokens {
+		if !isMacro(tokens, i, macros) {
+			continue
+		}
+		if contains(expanding, tok) {
+			return "", fmt.Errorf("macro '%s' is defined in terms of itself", tok)
+		}
+
+		body, err := expandMacros(macros[tok], macros, append(expanding, tok), opts)
+		if err != nil {
+			return "", err
+		}
+		if !opts.Postfix {
+			body = "(" + body + ")"
+		}
+		tokens[i] = body
+	}
+	return strings.Join(tokens, " "), nil
+}
+
+// tokenize splits the expression using the Go scanner, merging the "-" ">"
+// and "<-" ">" token pairs into the implication and equivalence operators
+func tokenize(expression string) []string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(expression))
+
+	var s scanner.Scanner
+	s.Init(file, []byte(expression), nil, 0)
+
+	var tokens []string
+	var end token.Pos
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			// Skip the automatically inserted semicolon
+			continue
+		}
+
+		// Merge adjacent "-" and ">" into "->", and "<-" and ">" into "<->"
+		if n := len(tokens); tok == token.GTR && n > 0 && pos == end {
+			if prev := tokens[n-1]; prev == "-" || prev == "<-" {
+				tokens[n-1] = prev + ">"
+				end = pos + 1
+				continue
+			}
+		}
+
+		text := lit
+		if text == "" {
+			text = tok.String()
+		}
+		tokens = append(tokens, text)
+		end = pos + token.Pos(len(text))
+	}
+
+	return tokens
+}
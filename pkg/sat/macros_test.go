@@ -0,0 +1,128 @@
+package sat
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandMacros(t *testing.T) {
+	macros := map[string]string{
+		"parity": "a ^ b ^ c",
+		"odd":    "parity && !d",
+	}
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"parity", "a ^ b ^ c"},
+		{"!parity || d", "!(a ^ b ^ c) || d"},
+		// A macro in a definition is expanded as well
+		{"odd || e", "((a ^ b ^ c) && !d) || e"},
+		{"parity && odd", "(a ^ b ^ c) && (a ^ b ^ c) && !d"},
+	}
+	symbols := []string{"a", "b", "c", "d", "e"}
+	for _, tt := range tests {
+		expanded, err := ExpandMacros(tt.formula, macros)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.formula, err)
+		}
+		if strings.Contains(expanded, "parity") || strings.Contains(expanded, "odd") {
+			t.Errorf("%s: got %q, which still uses a macro", tt.formula, expanded)
+		}
+		ok, diff, err := Equivalent(expanded, tt.want, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.formula, err)
+		}
+		if !ok {
+			t.Errorf("%s: got %q, which differs from %s on %v", tt.formula, expanded, tt.want, diff)
+		}
+	}
+
+	// A formula using no macro is left as it is
+	if got, err := ExpandMacros("a&&b", macros); err != nil || got != "a&&b" {
+		t.Errorf("a&&b: got %q, %v, want it unchanged", got, err)
+	}
+}
+
+func TestExpandMacrosCycle(t *testing.T) {
+	macros := map[string]string{
+		"self": "!self",
+		"x":    "y && a",
+		"y":    "b || z",
+		"z":    "x",
+		"ok":   "a && b",
+	}
+	for _, formula := range []string{"self", "x", "ok || z"} {
+		if _, err := ExpandMacros(formula, macros); err == nil || !strings.Contains(err.Error(), "defined in terms of itself") {
+			t.Errorf("%s: got error %v, want one for the cyclic definition", formula, err)
+		}
+	}
+	if _, err := ExpandMacros("ok && ok", macros); err != nil {
+		t.Errorf("ok && ok: %v", err)
+	}
+}
+
+func TestExpandMacrosSyntaxErrors(t *testing.T) {
+	macros := map[string]string{"p": "a || b", "broken": "a || )", "outer": "broken && c"}
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		// The columns are those of the formula as written, not expanded
+		{"p   &&  ) c", "syntax error near column 9: expected operand, found ')'"},
+		// And those of the definition for the errors in a definition
+		{"broken && c", "macro 'broken': error parsing expression: syntax error near column 6"},
+		{"outer", "macro 'broken': error parsing expression: syntax error near column 6"},
+	}
+	for _, tt := range tests {
+		if _, err := ExpandMacros(tt.formula, macros); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want %q", tt.formula, err, tt.want)
+		}
+	}
+}
+
+func TestExpandMacrosCalls(t *testing.T) {
+	// The name of a call is not a use of a macro
+	macros := map[string]string{"p": "a || b"}
+	for formula, want := range map[string]string{
+		"p(a)":        "p(a)",
+		"p && xor(p)": "(a || b) && xor ( (a || b) )",
+	} {
+		if got, err := ExpandMacros(formula, macros); err != nil || got != want {
+			t.Errorf("%s: got %q, %v, want %q", formula, got, err, want)
+		}
+	}
+	if _, err := Eval("p(a)", map[string]bool{"a": true}); errorString(err) != "unknown function 'p'" {
+		t.Errorf("p(a): got error %v", err)
+	}
+
+	// Nor can a macro take the name of a builtin
+	if _, err := ExpandMacros("xor(a, b)", map[string]string{"xor": "a"}); errorString(err) != "macro 'xor' is named like a builtin function" {
+		t.Errorf("xor(a, b): got error %v", err)
+	}
+}
+
+func TestExpandMacrosRPN(t *testing.T) {
+	macros := map[string]string{"both": "a b &&", "either": "both c ||"}
+	for formula, want := range map[string]string{
+		"both !":      "a b && !",
+		"either d ->": "a b && c || d ->",
+		"a b <->":     "a b <->",
+	} {
+		if got, err := ExpandMacrosRPN(formula, macros); err != nil || got != want {
+			t.Errorf("%s: got %q, %v, want %q", formula, got, err, want)
+		}
+	}
+	if _, err := ExpandMacrosRPN("both", map[string]string{"both": "a &&"}); errorString(err) != "macro 'both': missing operand for '&&'" {
+		t.Errorf("both: got error %v", err)
+	}
+}
+
+func TestTokenizeBiconditional(t *testing.T) {
+	tokens := tokenize("a<->b <- > c")
+	want := []string{"a", "<->", "b", "<-", ">", "c"}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("got tokens %q, want %q", tokens, want)
+	}
+}
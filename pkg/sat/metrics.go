@@ -0,0 +1,106 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// FormulaMetrics measures how complex a formula is. The depth counts the
+// nodes on the longest path from the root to a leaf, as in PrintTree, so
+// parentheses don't add to it
+type FormulaMetrics struct {
+	Depth     int `json:"depth"`
+	Binary    int `json:"binary"`
+	Negations int `json:"negations"`
+	Variables int `json:"variables"`
+}
+
+// Metrics returns the metrics of the formula, walking its syntax tree
+func Metrics(formula string) (FormulaMetrics, error) {
+	expr, err := parseChecked(formula)
+	if err != nil {
+		return FormulaMetrics{}, err
+	}
+
+	var m FormulaMetrics
+	if m.Depth, err = measure(expr, &m); err != nil {
+		return FormulaMetrics{}, err
+	}
+	m.Variables = len(collectSymbols(expr))
+	return m, nil
+}
+
+// measure counts the operators of the expression into the metrics, returning
+// its depth
+func measure(node ast.Expr, m *FormulaMetrics) (int, error) {
+	var children []ast.Expr
+	switch expr := node.(type) {
+	case *ast.Ident, *ast.BasicLit:
+	case *ast.UnaryExpr:
+		if expr.Op != token.NOT {
+			return 0, fmt.Errorf("unsupported unary operator: %s", expr.Op)
+		}
+		m.Negations++
+		children = []ast.Expr{expr.X}
+	case *ast.BinaryExpr:
+		m.Binary++
+		children = []ast.Expr{expr.X, expr.Y}
+	case *ast.CallExpr:
+		children = expr.Args
+	case *ast.ParenExpr:
+		return measure(expr.X, m)
+	default:
+		return 0, fmt.Errorf("unsupported expression type: %T", node)
+	}
+
+	depth := 0
+	for _, child := range children {
+		d, err := measure(child, m)
+		if err != nil {
+			return 0, err
+		}
+		if d > depth {
+			depth = d
+		}
+	}
+	return depth + 1, nil
+}
+
+// CommonSubexpressions returns how many times each distinct subexpression of
+// the formula occurs in it, symbols included, by its normal form, so that the
+// operands of a commutative operator can come in any order. The counts above
+// one show the structure that could be shared
+func CommonSubexpressions(formula string) (map[string]int, error) {
+	expr, err := parseChecked(formula)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	countSubexpressions(normalize(expr), counts)
+	return counts, nil
+}
+
+// countSubexpressions adds the expression and its operands to the counts
+func countSubexpressions(node ast.Expr, counts map[string]int) {
+	switch expr := node.(type) {
+	case *ast.ParenExpr:
+		// Parentheses only group the expression they hold
+		countSubexpressions(expr.X, counts)
+		return
+	case *ast.UnaryExpr:
+		countSubexpressions(expr.X, counts)
+	case *ast.BinaryExpr:
+		countSubexpressions(expr.X, counts)
+		countSubexpressions(expr.Y, counts)
+	case *ast.CallExpr:
+		// Neither the name of the function nor the count of a cardinality
+		// constraint are subexpressions. The call was checked when parsing
+		call, _ := resolveCall(expr)
+		for _, arg := range call.operands {
+			countSubexpressions(arg, counts)
+		}
+	}
+	counts[formatExpr(node)]++
+}
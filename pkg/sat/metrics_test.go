@@ -0,0 +1,70 @@
+package sat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    FormulaMetrics
+	}{
+		{"a && (b || !c)", FormulaMetrics{Depth: 4, Binary: 2, Negations: 1, Variables: 3}},
+		{"a", FormulaMetrics{Depth: 1, Variables: 1}},
+		{"((a))", FormulaMetrics{Depth: 1, Variables: 1}},
+		{"!!a || a", FormulaMetrics{Depth: 4, Binary: 1, Negations: 2, Variables: 1}},
+		{"true && false", FormulaMetrics{Depth: 2, Binary: 1}},
+		{"a && b && c && d", FormulaMetrics{Depth: 4, Binary: 3, Variables: 4}},
+		{"xor(a, b, !c)", FormulaMetrics{Depth: 3, Negations: 1, Variables: 3}},
+		// The formula is measured as written, not rewritten
+		{"a -> b", FormulaMetrics{Depth: 2, Binary: 1, Variables: 2}},
+		{"a <-> !b", FormulaMetrics{Depth: 3, Binary: 1, Negations: 1, Variables: 2}},
+		{"atleast(2, a, b, c)", FormulaMetrics{Depth: 2, Variables: 3}},
+	}
+	for _, test := range tests {
+		got, err := Metrics(test.formula)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.formula, got, test.want)
+		}
+	}
+
+	if _, err := Metrics("a &&"); err == nil {
+		t.Errorf("expected an error for a formula that doesn't parse")
+	}
+}
+
+func TestCommonSubexpressions(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    map[string]int
+	}{
+		{"(a && b) || (a && b)", map[string]int{"a": 2, "b": 2, "a && b": 2, "a && b || a && b": 1}},
+		// The operands of a commutative operator can come in any order
+		{"(a && b) || (b && a)", map[string]int{"a": 2, "b": 2, "a && b": 2, "a && b || a && b": 1}},
+		{"!(a || b) && (b || a) && c", map[string]int{
+			"a": 2, "b": 2, "c": 1, "a || b": 2, "!(a || b)": 1,
+			"!(a || b) && (a || b)": 1, "!(a || b) && (a || b) && c": 1,
+		}},
+		{"a", map[string]int{"a": 1}},
+		// The formula is counted as written, not rewritten
+		{"(a -> b) && (a -> b)", map[string]int{"a": 2, "b": 2, "a -> b": 2, "(a -> b) && (a -> b)": 1}},
+		{"atleast(1, a, a && 1)", map[string]int{"a": 2, "1": 1, "1 && a": 1, "atleast(1, a, 1 && a)": 1}},
+	}
+	for _, tt := range tests {
+		counts, err := CommonSubexpressions(tt.formula)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.formula, err)
+		}
+		if !reflect.DeepEqual(counts, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.formula, counts, tt.want)
+		}
+	}
+
+	if _, err := CommonSubexpressions("a &&"); err == nil {
+		t.Errorf("expected an error for an invalid formula")
+	}
+}
@@ -0,0 +1,127 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// ToNNF returns a formula in negation normal form equivalent to the given
+// one, made only of conjunctions, disjunctions and literals, by pushing the
+// negations down to the symbols with De Morgan's laws. The exclusive or and
+// the equivalence are rewritten as disjunctions of the cases they hold in,
+// and the boolean literals are folded away unless the formula is constant
+func ToNNF(formula string) (string, error) {
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return "", err
+	}
+	nnf, err := toNNF(expr, false)
+	if err != nil {
+		return "", err
+	}
+	return formatExpr(nnf), nil
+}
+
+// toNNF converts the expression, or its negation if negated, to NNF
+func toNNF(node ast.Expr, negated bool) (ast.Expr, error) {
+	return nnfMemo{}.toNNF(node, negated)
+}
+
+// nnfMemo holds the NNF of the nodes already converted, so that the shared
+// nodes of an expanded cardinality constraint are only converted once
+type nnfMemo map[polarNode]ast.Expr
+
+// toNNF returns the memoized conversion of the expression, or of its negation
+// if negated
+func (m nnfMemo) toNNF(node ast.Expr, negated bool) (ast.Expr, error) {
+	key := polarNode{node: node, negated: negated}
+	if result, ok := m[key]; ok {
+		return result, nil
+	}
+	result, err := m.convert(node, negated)
+	if err != nil {
+		return nil, err
+	}
+	m[key] = result
+	return result, nil
+}
+
+// convert converts the expression, or its negation if negated, walking its
+// operands through the memo
+func (m nnfMemo) convert(node ast.Expr, negated bool) (ast.Expr, error) {
+	switch expr := node.(type) {
+	case *ast.Ident:
+		if value, ok := isConstant(expr); ok {
+			return constant(value != negated), nil
+		}
+		if negated {
+			return &ast.UnaryExpr{Op: token.NOT, X: expr}, nil
+		}
+		return expr, nil
+
+	case *ast.UnaryExpr:
+		if expr.Op != token.NOT {
+			return nil, fmt.Errorf("unsupported unary operator: %s", expr.Op)
+		}
+		return m.toNNF(expr.X, !negated)
+
+	case *ast.BinaryExpr:
+		switch expr.Op {
+		case token.LAND, token.LOR:
+			x, err := m.toNNF(expr.X, negated)
+			if err != nil {
+				return nil, err
+			}
+			y, err := m.toNNF(expr.Y, negated)
+			if err != nil {
+				return nil, err
+			}
+
+			// By De Morgan a negated conjunction is a disjunction and
+			// vice versa
+			if (expr.Op == token.LAND) != negated {
+				return conjoin(x, y), nil
+			}
+			return disjoin(x, y), nil
+
+		case token.XOR, token.NEQ, token.EQL:
+			// x != y is (x && !y) || (!x && y), while x == y is
+			// (x && y) || (!x && !y)
+			negations := [2][2]bool{{false, true}, {true, false}}
+			if (expr.Op == token.EQL) != negated {
+				negations = [2][2]bool{{false, false}, {true, true}}
+			}
+
+			result := constant(false)
+			for _, n := range negations {
+				x, err := m.toNNF(expr.X, n[0])
+				if err != nil {
+					return nil, err
+				}
+				y, err := m.toNNF(expr.Y, n[1])
+				if err != nil {
+					return nil, err
+				}
+				result = disjoin(result, conjoin(x, y))
+			}
+			return result, nil
+
+		default:
+			return nil, fmt.Errorf("unsupported binary operator: %s", expr.Op)
+		}
+
+	case *ast.CallExpr:
+		lowered, err := lowerCall(expr, negated)
+		if err != nil {
+			return nil, err
+		}
+		return m.toNNF(lowered, false)
+
+	case *ast.ParenExpr:
+		return m.toNNF(expr.X, negated)
+
+	default:
+		return nil, fmt.Errorf("unsupported expression type: %T", node)
+	}
+}
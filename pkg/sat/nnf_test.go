@@ -0,0 +1,114 @@
+package sat
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+)
+
+// isNNF reports whether the formula is made only of conjunctions,
+// disjunctions and literals
+func isNNF(t *testing.T, formula string) bool {
+	t.Helper()
+	expr, err := parseFormula(formula)
+	if err != nil {
+		t.Fatalf("%s: %v", formula, err)
+	}
+	nnf := true
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.UnaryExpr:
+			if _, ok := n.X.(*ast.Ident); !ok || n.Op != token.NOT {
+				nnf = false
+			}
+		case *ast.BinaryExpr:
+			if n.Op != token.LAND && n.Op != token.LOR {
+				nnf = false
+			}
+		case *ast.CallExpr:
+			nnf = false
+		}
+		return nnf
+	})
+	return nnf
+}
+
+func TestToNNF(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"!(a && b)", "!a || !b"},
+		{"!(a || !b)", "!a && b"},
+		{"!!a", "a"},
+		{"!(a -> b)", "a && !b"},
+		{"a && true", "a"},
+		{"!true", "false"},
+	}
+	for _, tt := range tests {
+		nnf, err := ToNNF(tt.formula)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.formula, err)
+		}
+		if nnf != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.formula, nnf, tt.want)
+		}
+	}
+
+	for _, formula := range []string{
+		"!(a && b)",
+		"!(a || !b)",
+		"!!!(a && !!b)",
+		"!(a ^ b)",
+		"a <-> !c",
+		"!(a && (b || !c))",
+		"!(a -> (b <-> c))",
+		"atleast(2, a, b, c)",
+		"!ite(a, b, c)",
+		"a || !a && false",
+	} {
+		nnf, err := ToNNF(formula)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		if !isNNF(t, nnf) {
+			t.Errorf("%s: %s is not in NNF", formula, nnf)
+		}
+		ok, witness, err := Equivalent(formula, nnf, []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("%s: %s disagrees on %v", formula, nnf, witness)
+		}
+	}
+}
+
+func TestToNNFRandom(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	for seed := int64(0); seed < 200; seed++ {
+		formula := RandomFormula(symbols, 4, seed)
+		nnf, err := ToNNF(formula)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		if !isNNF(t, nnf) {
+			t.Errorf("%s: %s is not in NNF", formula, nnf)
+		}
+		ok, witness, err := Equivalent(formula, nnf, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("%s: %s disagrees on %v", formula, nnf, witness)
+		}
+	}
+}
+
+func TestToNNFErrors(t *testing.T) {
+	for _, formula := range []string{"a &&", "-a", "f(a)", "a + b"} {
+		if _, err := ToNNF(formula); err == nil {
+			t.Errorf("%s: expected an error", formula)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package sat
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+)
+
+// Normalize returns the canonical form of the formula: the operands of the
+// commutative operators are sorted, redundant parentheses are removed and
+// double negations are folded. The normalization is structural, so formulas
+// that are only equivalent by their truth tables may still differ
+func Normalize(formula string) (string, error) {
+	expr, err := parseChecked(formula)
+	if err != nil {
+		return "", err
+	}
+	return formatExpr(normalize(expr)), nil
+}
+
+// normalize returns the canonical form of the expression as a new expression.
+// Nodes it doesn't know are kept as they are
+func normalize(node ast.Expr) ast.Expr {
+	switch expr := node.(type) {
+	case *ast.ParenExpr:
+		// The needed parentheses are added back when rebuilding
+		return normalize(expr.X)
+
+	case *ast.UnaryExpr:
+		if expr.Op != token.NOT {
+			return expr
+		}
+		x := normalize(expr.X)
+		if inner, ok := x.(*ast.UnaryExpr); ok && inner.Op == token.NOT {
+			return inner.X
+		}
+		return &ast.UnaryExpr{Op: token.NOT, X: group(x)}
+
+	case *ast.BinaryExpr:
+		switch expr.Op {
+		case token.LAND, token.LOR, token.XOR, token.NEQ, token.EQL, opIff:
+		case opImplies:
+			// The premise and the conclusion can't be swapped
+			return &ast.BinaryExpr{X: parenthesize(normalize(expr.X), expr.Op), Op: expr.Op, Y: parenthesize(normalize(expr.Y), expr.Op)}
+		default:
+			return expr
+		}
+
+		// Chains of the same operator are associative, so all of their
+		// operands can be sorted together
+		var operands []ast.Expr
+		for _, operand := range flatten(expr, expr.Op) {
+			operands = append(operands, normalize(operand))
+		}
+
+		keys := make(map[ast.Expr]string, len(operands))
+		for _, operand := range operands {
+			keys[operand] = formatExpr(operand)
+		}
+		sort.SliceStable(operands, func(i, j int) bool {
+			return keys[operands[i]] < keys[operands[j]]
+		})
+
+		result := parenthesize(operands[0], expr.Op)
+		for _, operand := range operands[1:] {
+			result = &ast.BinaryExpr{X: result, Op: expr.Op, Y: parenthesize(operand, expr.Op)}
+		}
+		return result
+
+	case *ast.CallExpr:
+		args := make([]ast.Expr, len(expr.Args))
+		for i, arg := range expr.Args {
+			args[i] = normalize(arg)
+		}
+		return &ast.CallExpr{Fun: expr.Fun, Args: args}
+
+	default:
+		return expr
+	}
+}
+
+// flatten returns the operands of the chain of op rooted at the expression,
+// looking through parentheses
+func flatten(node ast.Expr, op token.Token) []ast.Expr {
+	switch expr := node.(type) {
+	case *ast.ParenExpr:
+		return flatten(expr.X, op)
+	case *ast.BinaryExpr:
+		if expr.Op == op {
+			return append(flatten(expr.X, op), flatten(expr.Y, op)...)
+		}
+	}
+	return []ast.Expr{node}
+}
+
+// parenthesize wraps the operand of op in parentheses if it is a binary
+// expression that doesn't bind tighter than op
+func parenthesize(operand ast.Expr, op token.Token) ast.Expr {
+	if inner, ok := operand.(*ast.BinaryExpr); ok && precedence(inner.Op) <= precedence(op) {
+		return &ast.ParenExpr{X: operand}
+	}
+	return operand
+}
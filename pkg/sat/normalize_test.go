@@ -0,0 +1,73 @@
+package sat
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"b && a", "a && b"},
+		{"(a || b) || c", "a || b || c"},
+		{"c || (b || a)", "a || b || c"},
+		{"!!a", "a"},
+		{"!!!a", "!a"},
+		{"(a)", "a"},
+		{"b || (a && c)", "a && c || b"},
+		{"c && (b || a)", "(a || b) && c"},
+		{"b == a", "a == b"},
+		// The formula is normalized as written, not rewritten
+		{"a -> b", "a -> b"},
+		{"(b && a) -> (c || a)", "a && b -> a || c"},
+		{"b <-> (c <-> a)", "a <-> b <-> c"},
+		{"atleast(2, c, b, a)", "atleast(2, c, b, a)"},
+		{"atleast(2, b && a, c)", "atleast(2, a && b, c)"},
+	}
+	for _, test := range tests {
+		got, err := Normalize(test.formula)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.formula, got, test.want)
+		}
+
+		// The normal form is its own normal form, and means the same
+		again, err := Normalize(got)
+		if err != nil {
+			t.Fatalf("%s: %v", got, err)
+		}
+		if again != got {
+			t.Errorf("%s: normalized again to %q", got, again)
+		}
+		equivalent, counter, err := Equivalent(test.formula, got, []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equivalent {
+			t.Errorf("%s: normalized to %q, which differs on %v", test.formula, got, counter)
+		}
+	}
+}
+
+func TestNormalizeSameForm(t *testing.T) {
+	forms := [][]string{
+		{"a && b && c", "c && (b && a)", "(b && c) && a", "!!(a && b) && c"},
+		{"a || !b", "!b || a", "(!b) || (a)"},
+	}
+	for _, formulas := range forms {
+		want, err := Normalize(formulas[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, formula := range formulas[1:] {
+			got, err := Normalize(formula)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("%s: got %q, want %q like %s", formula, got, want, formulas[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// The implication and the equivalence have no Go counterpart, so the parsed
+// formulas hold them as operators of their own, outside the Go tokens
+const (
+	opImplies token.Token = -1 - iota
+	opIff
+)
+
+// operatorString returns how the operator is written in a formula
+func operatorString(op token.Token) string {
+	switch op {
+	case opImplies:
+		return "->"
+	case opIff:
+		return "<->"
+	}
+	return op.String()
+}
+
+// precedence returns how tightly the binary operator binds. The equivalence
+// binds looser than the implication, and both looser than the Go operators
+func precedence(op token.Token) int {
+	switch op {
+	case opIff:
+		return 1
+	case opImplies:
+		return 2
+	}
+	return op.Precedence() + 2
+}
+
+// rightAssociative reports whether the chains of the operator group to the
+// right, as those of the implication do
+func rightAssociative(op token.Token) bool {
+	return op == opImplies
+}
+
+// standIns maps the implication and the equivalence to the Go operators
+// standing for them while parsing
+var standIns = map[token.Token]token.Token{opImplies: token.LEQ, opIff: token.GEQ}
+
+// preprocess replaces the implications "->" with "<=" and the equivalences
+// "<->" with ">= ", Go operators of the same length, so that parser.ParseExpr
+// understands the expression and the positions it reports still match it. The
+// operators that were replaced are returned by their offset
+func preprocess(expression string) (string, map[int]token.Token) {
+	if !strings.Contains(expression, "->") {
+		return expression, nil
+	}
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(expression))
+
+	var s scanner.Scanner
+	s.Init(file, []byte(expression), nil, 0)
+
+	var src []byte
+	replaced := make(map[int]token.Token)
+	var prev token.Token
+	var prevOffset, end int
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			// Skip the automatically inserted semicolon
+			continue
+		}
+		offset := file.Offset(pos)
+
+		// Merge adjacent "-" and ">" into "->", and "<-" and ">" into "<->"
+		if tok == token.GTR && offset == end && (prev == token.SUB || prev == token.ARROW) {
+			if src == nil {
+				src = []byte(expression)
+			}
+			op := opImplies
+			if prev == token.ARROW {
+				op = opIff
+			}
+			// Pad the Go operator to the length of the one it stands for
+			copy(src[prevOffset:], fmt.Sprintf("%-*s", len(operatorString(op)), standIns[op]))
+			replaced[prevOffset] = op
+			prev = token.ILLEGAL
+			continue
+		}
+
+		prev, prevOffset = tok, offset
+		end = offset + len(tok.String())
+		if lit != "" {
+			end = offset + len(lit)
+		}
+	}
+
+	if src == nil {
+		return expression, nil
+	}
+	return string(src), replaced
+}
+
+// regroup rebuilds the chains of binary operators of the parsed expression,
+// turning the replaced operators back into the implications and equivalences
+// and grouping them with their own precedence. The expression is returned as
+// it is when nothing was replaced
+func regroup(node ast.Expr, fset *token.FileSet, replaced map[int]token.Token) ast.Expr {
+	if len(replaced) == 0 {
+		return node
+	}
+
+	switch expr := node.(type) {
+	case *ast.ParenExpr:
+		return &ast.ParenExpr{Lparen: expr.Lparen, X: regroup(expr.X, fset, replaced), Rparen: expr.Rparen}
+
+	case *ast.UnaryExpr:
+		return &ast.UnaryExpr{OpPos: expr.OpPos, Op: expr.Op, X: regroup(expr.X, fset, replaced)}
+
+	case *ast.CallExpr:
+		args := make([]ast.Expr, len(expr.Args))
+		for i, arg := range expr.Args {
+			args[i] = regroup(arg, fset, replaced)
+		}
+		return &ast.CallExpr{Fun: expr.Fun, Lparen: expr.Lparen, Args: args, Ellipsis: expr.Ellipsis, Rparen: expr.Rparen}
+
+	case *ast.BinaryExpr:
+		// The parser grouped the chain with the precedence of the Go
+		// operators, which still holds its operands in their order
+		c := &chain{}
+		c.collect(expr, fset, replaced)
+		return c.parse(0)
+
+	default:
+		return expr
+	}
+}
+
+// chain is a sequence of operands joined by binary operators, as written
+type chain struct {
+	operands []ast.Expr
+	ops      []chainOp
+	next     int // The operand to parse next, followed by the operator of the same index
+}
+
+// chainOp is an operator of a chain and its position
+type chainOp struct {
+	pos token.Pos
+	op  token.Token
+}
+
+// collect appends the operands and the operators of the binary expression,
+// regrouping the operands on their own
+func (c *chain) collect(node ast.Expr, fset *token.FileSet, replaced map[int]token.Token) {
+	expr, ok := node.(*ast.BinaryExpr)
+	if !ok {
+		c.operands = append(c.operands, regroup(node, fset, replaced))
+		return
+	}
+
+	op := expr.Op
+	if original, ok := replaced[fset.Position(expr.OpPos).Offset]; ok {
+		op = original
+	}
+	c.collect(expr.X, fset, replaced)
+	c.ops = append(c.ops, chainOp{pos: expr.OpPos, op: op})
+	c.collect(expr.Y, fset, replaced)
+}
+
+// parse groups the rest of the chain into the operators binding at least as
+// tightly as the precedence, by precedence climbing
+func (c *chain) parse(prec int) ast.Expr {
+	x := c.operands[c.next]
+	for c.next < len(c.ops) && precedence(c.ops[c.next].op) >= prec {
+		op := c.ops[c.next]
+		c.next++
+		next := precedence(op.op) + 1
+		if rightAssociative(op.op) {
+			next--
+		}
+		y := c.parse(next)
+		x = &ast.BinaryExpr{X: x, OpPos: op.pos, Op: op.op, Y: y}
+	}
+	return x
+}
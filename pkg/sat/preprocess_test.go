@@ -0,0 +1,89 @@
+package sat
+
+import (
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestEvalImplication(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	// False only when a is true and b is false
+	checkTruthTable(t, "a -> b", symbols, func(v []bool) bool { return !(v[0] && !v[1]) })
+	// Right associative
+	checkTruthTable(t, "a -> b -> c", symbols, func(v []bool) bool { return !v[0] || (!v[1] || v[2]) })
+	checkTruthTable(t, "(a -> b) -> c", symbols, func(v []bool) bool { return !(!v[0] || v[1]) || v[2] })
+	// Looser than the other operators
+	checkTruthTable(t, "a && b -> c", symbols, func(v []bool) bool { return !(v[0] && v[1]) || v[2] })
+	checkTruthTable(t, "a -> b || c", symbols, func(v []bool) bool { return !v[0] || v[1] || v[2] })
+	checkTruthTable(t, "!a->b", symbols, func(v []bool) bool { return v[0] || v[1] })
+}
+
+func TestPreprocessImplication(t *testing.T) {
+	tests := []struct {
+		formula  string
+		want     string
+		replaced map[int]token.Token
+	}{
+		{"a && b", "a && b", nil},
+		// The operators keep their length, so the positions don't move
+		{"a -> b", "a <= b", map[int]token.Token{2: opImplies}},
+		{"a->b -> c", "a<=b <= c", map[int]token.Token{1: opImplies, 5: opImplies}},
+	}
+	for _, test := range tests {
+		got, replaced := preprocess(test.formula)
+		if got != test.want || !reflect.DeepEqual(replaced, test.replaced) {
+			t.Errorf("%s: got %s and %v, want %s and %v", test.formula, got, replaced, test.want, test.replaced)
+		}
+	}
+
+	for _, formula := range []string{"a ->", "-> b", "a -> -> b"} {
+		if _, err := Eval(formula, map[string]bool{"a": true, "b": true}); err == nil {
+			t.Errorf("%s: expected an error", formula)
+		}
+	}
+}
+
+func TestRegroup(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		// The implication groups to the right, the equivalence to the left
+		{"a -> b -> c", "a -> b -> c"},
+		{"(a -> b) -> c", "(a -> b) -> c"},
+		{"a <-> b <-> c", "a <-> b <-> c"},
+		// Both bind looser than the Go operators, the equivalence loosest
+		{"a || b -> c && d", "a || b -> c && d"},
+		{"(a || b) -> c", "a || b -> c"},
+		{"a -> (b == c)", "a -> b == c"},
+		{"a -> b <-> c -> d", "a -> b <-> c -> d"},
+		{"(a <-> b) -> c", "(a <-> b) -> c"},
+		{"!(a -> b) && xor(c <-> d, a)", "!(a -> b) && xor(c <-> d, a)"},
+	}
+	for _, test := range tests {
+		expr, err := parseSyntax(test.formula)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if got := formatExpr(expr); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.formula, got, test.want)
+		}
+	}
+
+	// The operators written in Go stay the unsupported comparisons
+	if _, err := Eval("a <= b", map[string]bool{"a": true, "b": true}); err == nil {
+		t.Errorf("a <= b: expected an error")
+	}
+}
+
+func TestEvalBiconditional(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	// True exactly when both sides agree, over the four combinations
+	checkTruthTable(t, "a <-> b", symbols[:2], func(v []bool) bool { return v[0] == v[1] })
+	checkTruthTable(t, "a <-> !a", symbols[:1], func(v []bool) bool { return false })
+	checkTruthTable(t, "a <-> b <-> c", symbols, func(v []bool) bool { return v[0] == (v[1] == v[2]) })
+	// Looser than the implication
+	checkTruthTable(t, "a -> b <-> !b -> !a", symbols[:2], func(v []bool) bool { return true })
+	checkTruthTable(t, "a <-> b && c", symbols, func(v []bool) bool { return v[0] == (v[1] && v[2]) })
+}
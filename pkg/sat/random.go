@@ -0,0 +1,37 @@
+package sat
+
+import (
+	"go/ast"
+	"go/token"
+	"math/rand"
+)
+
+// randomOperators are the binary operators the random formulas are made of
+var randomOperators = []token.Token{token.LAND, token.LOR, token.XOR, token.EQL, token.NEQ}
+
+// RandomFormula returns a random well formed formula over the symbols, with
+// at most depth operators nested. The same seed always gives the same
+// formula, and without symbols it is made of constants
+func RandomFormula(symbols []string, depth int, seed int64) string {
+	rng := rand.New(rand.NewSource(seed))
+	return formatExpr(randomExpr(rng, symbols, depth))
+}
+
+// randomExpr returns a random expression at most depth operators deep,
+// stopping early at a leaf every now and then
+func randomExpr(rng *rand.Rand, symbols []string, depth int) ast.Expr {
+	if depth <= 0 || rng.Intn(4) == 0 {
+		if len(symbols) == 0 {
+			return constant(rng.Intn(2) == 1)
+		}
+		return ast.NewIdent(symbols[rng.Intn(len(symbols))])
+	}
+
+	if rng.Intn(5) == 0 {
+		return &ast.UnaryExpr{Op: token.NOT, X: group(randomExpr(rng, symbols, depth-1))}
+	}
+	op := randomOperators[rng.Intn(len(randomOperators))]
+	x := randomExpr(rng, symbols, depth-1)
+	y := randomExpr(rng, symbols, depth-1)
+	return &ast.BinaryExpr{X: parenthesize(x, op), Op: op, Y: parenthesize(y, op)}
+}
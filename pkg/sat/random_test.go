@@ -0,0 +1,59 @@
+package sat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRandomFormula(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	distinct := make(map[string]bool)
+	for seed := int64(1); seed <= 200; seed++ {
+		formula := RandomFormula(symbols, 5, seed)
+		if again := RandomFormula(symbols, 5, seed); again != formula {
+			t.Errorf("seed %d: got %q, then %q", seed, formula, again)
+		}
+		distinct[formula] = true
+
+		expr, err := parseFormula(formula)
+		if err != nil {
+			t.Fatalf("seed %d: %s: %v", seed, formula, err)
+		}
+		// Only the symbols given are used
+		if err := checkSymbols(expr, symbols); err != nil {
+			t.Errorf("seed %d: %s: %v", seed, formula, err)
+		}
+
+		// The formula is printed with as few parentheses as possible, which
+		// can regroup the chains of an operator, so the depth is checked on
+		// the expression before that
+		var m FormulaMetrics
+		depth, err := measure(randomExpr(rand.New(rand.NewSource(seed)), symbols, 5), &m)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		// A leaf below at most five operators
+		if depth > 6 {
+			t.Errorf("seed %d: %s: got depth %d, want at most 6", seed, formula, depth)
+		}
+	}
+	if len(distinct) < 100 {
+		t.Errorf("got %d distinct formulas out of 200 seeds", len(distinct))
+	}
+}
+
+func TestRandomFormulaConstants(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		formula := RandomFormula(nil, 3, seed)
+		symbols, err := ExtractSymbols(formula)
+		if err != nil {
+			t.Fatalf("seed %d: %s: %v", seed, formula, err)
+		}
+		if len(symbols) != 0 {
+			t.Errorf("seed %d: %s: got symbols %v, want none", seed, formula, symbols)
+		}
+	}
+	if got := RandomFormula([]string{"a"}, 0, 1); got != "a" {
+		t.Errorf("depth 0: got %q, want a", got)
+	}
+}
@@ -0,0 +1,78 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// rpnOperators maps the binary operators accepted in postfix notation to
+// their Go counterpart. The implication is handled on its own
+var rpnOperators = map[string]token.Token{
+	"&&":  token.LAND,
+	"||":  token.LOR,
+	"^":   token.XOR,
+	"!=":  token.NEQ,
+	"==":  token.EQL,
+	"<->": token.EQL,
+}
+
+// FromRPN turns a formula written in postfix notation, with the tokens
+// separated by spaces as in "a b && !", into the usual infix notation
+func FromRPN(rpn string) (string, error) {
+	expr, err := parseRPN(rpn)
+	if err != nil {
+		return "", err
+	}
+	return formatExpr(expr), nil
+}
+
+// parseRPN builds the AST of the postfix formula directly with a stack,
+// without going through the Go parser
+func parseRPN(rpn string) (ast.Expr, error) {
+	var stack []ast.Expr
+	for _, tok := range strings.Fields(rpn) {
+		op, binary := rpnOperators[tok]
+		switch {
+		case tok == "!":
+			if len(stack) < 1 {
+				return nil, fmt.Errorf("missing operand for '%s'", tok)
+			}
+			stack[len(stack)-1] = negate(stack[len(stack)-1])
+
+		case binary || tok == "->":
+			if len(stack) < 2 {
+				return nil, fmt.Errorf("missing operand for '%s'", tok)
+			}
+			x, y := stack[len(stack)-2], stack[len(stack)-1]
+			stack = stack[:len(stack)-2]
+
+			// An implication holds when the premise is false or the
+			// conclusion true
+			if tok == "->" {
+				op, x = token.LOR, negate(x)
+			}
+			stack = append(stack, &ast.BinaryExpr{X: parenthesize(x, op), Op: op, Y: parenthesize(y, op)})
+
+		case token.IsIdentifier(tok):
+			stack = append(stack, ast.NewIdent(tok))
+
+		case tok == "0" || tok == "1":
+			// The constants as the infix formulas write them
+			stack = append(stack, &ast.BasicLit{Kind: token.INT, Value: tok})
+
+		default:
+			return nil, fmt.Errorf("unexpected token '%s' in postfix formula", tok)
+		}
+	}
+
+	switch len(stack) {
+	case 0:
+		return nil, fmt.Errorf("empty postfix formula")
+	case 1:
+		return stack[0], nil
+	default:
+		return nil, fmt.Errorf("missing operator for %d operands left in postfix formula", len(stack))
+	}
+}
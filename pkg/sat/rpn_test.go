@@ -0,0 +1,77 @@
+package sat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromRPN(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	tests := []struct {
+		rpn   string
+		infix string
+	}{
+		{"a", "a"},
+		{"a b &&", "a && b"},
+		{"a b && !", "!(a && b)"},
+		{"a b c || &&", "a && (b || c)"},
+		{"a b && c ||", "a && b || c"},
+		{"a ! b ! ||", "!a || !b"},
+		{"a b ->", "a -> b"},
+		{"a b -> c ->", "(a -> b) -> c"},
+		{"a b <-> c ^", "(a <-> b) ^ c"},
+		{"a b != c ==", "(a != b) == c"},
+		{"a true && false ||", "a && true || false"},
+		{"a 1 && b 0 || ->", "a && 1 -> b || 0"},
+	}
+	for _, test := range tests {
+		expr, err := parseRPN(test.rpn)
+		if err != nil {
+			t.Fatalf("%s: %v", test.rpn, err)
+		}
+		// The AST built from the postfix formula evaluates like the infix one
+		checkTruthTable(t, test.infix, symbols, func(v []bool) bool {
+			values := map[string]bool{"a": v[0], "b": v[1], "c": v[2]}
+			res, err := Evaluate(expr, values)
+			if err != nil {
+				t.Fatalf("%s: %v", test.rpn, err)
+			}
+			return res
+		})
+
+		// And so does its conversion
+		infix, err := FromRPN(test.rpn)
+		if err != nil {
+			t.Fatalf("%s: %v", test.rpn, err)
+		}
+		equivalent, counter, err := Equivalent(infix, test.infix, symbols)
+		if err != nil {
+			t.Fatalf("%s converted to %s: %v", test.rpn, infix, err)
+		}
+		if !equivalent {
+			t.Errorf("%s: got %q, want %q, they differ on %v", test.rpn, infix, test.infix, counter)
+		}
+	}
+}
+
+func TestFromRPNErrors(t *testing.T) {
+	tests := []struct {
+		rpn  string
+		want string
+	}{
+		{"", "empty postfix formula"},
+		{"&&", "missing operand for '&&'"},
+		{"a &&", "missing operand for '&&'"},
+		{"!", "missing operand for '!'"},
+		{"a b", "missing operator for 2 operands left"},
+		{"a b &", "unexpected token '&'"},
+		{"a (b) &&", "unexpected token '(b)'"},
+		{"a 2 &&", "unexpected token '2'"},
+	}
+	for _, test := range tests {
+		_, err := FromRPN(test.rpn)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%q: got error %v, want %q", test.rpn, err, test.want)
+		}
+	}
+}
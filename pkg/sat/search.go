@@ -0,0 +1,196 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxSymbols is the largest number of symbols whose combinations can still
+// be counted by an int: 2^63 already overflows a 64 bit one
+const maxSymbols = bits.UintSize - 2
+
+// progressInterval is the time between two calls to Options.Progress
+const progressInterval = 200 * time.Millisecond
+
+// Stats describes the work of a search: the size of the space of the
+// combinations of the symbols and how many of them were evaluated, fewer if
+// the search stopped at a satisfying one. With the workers evaluating
+// combinations in parallel, a few past the satisfying one may be counted
+type Stats struct {
+	Variables      int  `json:"variables"`
+	Combinations   int  `json:"combinations"`
+	Evaluated      int  `json:"evaluated"`
+	ShortCircuited bool `json:"short_circuited"`
+}
+
+// countCombinations returns the number of combinations of the symbols,
+// refusing symbol sets too large to be enumerated
+func countCombinations(symbols []string) (int, error) {
+	if len(symbols) > maxSymbols {
+		return 0, fmt.Errorf("too many symbols for brute force (max %d): %d", maxSymbols, len(symbols))
+	}
+	// Without symbols there is still the single, empty, combination
+	return 1 << len(symbols), nil
+}
+
+// combination maps each symbol to the value of its bit in i
+func combination(i int, symbols []string) map[string]bool {
+	values := make(map[string]bool, len(symbols))
+	setCombination(values, i, symbols)
+	return values
+}
+
+// setCombination overwrites values with the combination i of the symbols
+func setCombination(values map[string]bool, i int, symbols []string) {
+	for j, symbol := range symbols {
+		value := (i>>j)&1 == 1
+		values[symbol] = value
+	}
+}
+
+// worker evaluates the combinations it receives until there are no more,
+// sending back the satisfying ones and counting them all in explored
+func worker(ctx context.Context, eval func(uint64) bool, jobs <-chan int, results chan<- int, explored *atomic.Int64, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case i, ok := <-jobs:
+			if !ok {
+				return
+			}
+			// If the combination satisfies the formula, send its index to the channel
+			if eval(uint64(i)) {
+				results <- i
+			}
+			explored.Add(1)
+		}
+	}
+}
+
+// search evaluates the combinations of the symbols with a pool of workers and
+// returns the satisfying combination with the smallest index, or nil if the
+// formula is unsatisfiable. It gives up with the context error as soon as the
+// context is done. The options tell it how many workers to run and where
+// to report its progress
+func search(ctx context.Context, formula string, symbols []string, opts Options) (map[string]bool, error) {
+	model, _, err := searchStats(ctx, formula, symbols, opts)
+	return model, err
+}
+
+// searchStats is search, also reporting the work it did
+func searchStats(ctx context.Context, formula string, symbols []string, opts Options) (map[string]bool, Stats, error) {
+	// Compile the formula once for all the workers
+	expr, err := opts.parseFormula(formula)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+	// Fail before starting the workers if some identifier isn't declared
+	if err := checkSymbols(expr, symbols); err != nil {
+		return nil, Stats{}, err
+	}
+	eval, err := compile(expr, symbols)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+
+	nCombinations, err := countCombinations(symbols)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+
+	jobs := make(chan int)
+	results := make(chan int)
+	stop := make(chan struct{})
+	fed := make(chan error, 1)
+	var wg sync.WaitGroup
+
+	// Feed the combinations in ascending order until told to stop. Every
+	// index smaller than a satisfying one has then already been handed out
+	go func() {
+		defer close(jobs)
+		for i := 0; i < nCombinations; i++ {
+			select {
+			case jobs <- i:
+			case <-stop:
+				fed <- nil
+				return
+			case <-ctx.Done():
+				fed <- ctx.Err()
+				return
+			}
+		}
+		fed <- nil
+	}()
+
+	// The workers share the counter, read by the reporter at its own pace
+	var explored atomic.Int64
+	if report := opts.Progress; report != nil {
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			ticker := time.NewTicker(progressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					report(int(explored.Load()), nCombinations)
+				case <-done:
+					return
+				}
+			}
+		}()
+		defer func() {
+			close(done)
+			<-stopped
+		}()
+	}
+
+	// Launch worker threads
+	for w := 0; w < opts.workers(); w++ {
+		wg.Add(1)
+		go worker(ctx, eval, jobs, results, &explored, &wg)
+	}
+
+	// Wait for the workers to finish in a goroutine
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Stop handing out combinations at the first result, but keep reading
+	// until the workers are done so that the smallest satisfying index wins
+	// regardless of the order they finish in
+	best := -1
+	for i := range results {
+		if best < 0 {
+			close(stop)
+		}
+		if best < 0 || i < best {
+			best = i
+		}
+	}
+
+	// The workers may have quit before the smaller indexes were evaluated
+	if err := ctx.Err(); err != nil {
+		return nil, Stats{}, err
+	}
+	if err := <-fed; err != nil {
+		return nil, Stats{}, err
+	}
+	// The workers are done, so the count is final
+	stats := Stats{Variables: len(symbols), Combinations: nCombinations, Evaluated: int(explored.Load())}
+	stats.ShortCircuited = stats.Evaluated < stats.Combinations
+	if best < 0 {
+		return nil, stats, nil
+	}
+
+	return combination(best, symbols), stats, nil
+}
@@ -0,0 +1,222 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// manySymbols returns the symbols s0, s1, ... up to n of them
+func manySymbols(n int) []string {
+	symbols := make([]string, n)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("s%d", i)
+	}
+	return symbols
+}
+
+func TestCountCombinations(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 10, 31, maxSymbols} {
+		got, err := countCombinations(manySymbols(n))
+		if err != nil {
+			t.Fatalf("%d symbols: %v", n, err)
+		}
+		if want := 1 << n; got != want || got <= 0 {
+			t.Errorf("%d symbols: got %d combinations, want %d", n, got, want)
+		}
+	}
+
+	if _, err := countCombinations(manySymbols(maxSymbols + 1)); err == nil {
+		t.Errorf("%d symbols: expected an error", maxSymbols+1)
+	}
+}
+
+func TestSolveSymbolLimit(t *testing.T) {
+	symbols := manySymbols(maxSymbols)
+	result, err := Solve(strings.Join(symbols, " || "), symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Satisfiable {
+		t.Errorf("%d symbols: expected satisfiable", maxSymbols)
+	}
+
+	// Past the limit the search must fail rather than find nothing
+	symbols = manySymbols(maxSymbols + 1)
+	if _, err := Solve(strings.Join(symbols, " || "), symbols); err == nil {
+		t.Errorf("%d symbols: expected an error", maxSymbols+1)
+	}
+}
+
+func TestSearchWorkers(t *testing.T) {
+	symbols := manySymbols(8)
+	formula := "s3 && s5 && !s7"
+	want := combination(1<<3|1<<5, symbols)
+	for _, workers := range []int{-1, 0, 1, 2, 7, 64} {
+		model, err := search(context.Background(), formula, symbols, Options{Workers: workers})
+		if err != nil {
+			t.Fatalf("%d workers: %v", workers, err)
+		}
+		if !reflect.DeepEqual(model, want) {
+			t.Errorf("%d workers: got %v, want %v", workers, model, want)
+		}
+	}
+}
+
+func TestOptionsWorkers(t *testing.T) {
+	if got := (Options{Workers: 3}).workers(); got != 3 {
+		t.Errorf("got %d workers, want 3", got)
+	}
+	if got, want := (Options{}).workers(), runtime.NumCPU(); got != want {
+		t.Errorf("got %d workers by default, want %d", got, want)
+	}
+}
+
+func TestArrange(t *testing.T) {
+	tests := []struct {
+		symbols, order, want []string
+	}{
+		{[]string{"c", "a", "b"}, nil, []string{"a", "b", "c"}},
+		{[]string{"a", "b", "c"}, []string{"c"}, []string{"c", "a", "b"}},
+		{[]string{"a", "b", "c"}, []string{"b", "x", "a", "b"}, []string{"b", "a", "c"}},
+		{[]string{}, []string{"a"}, []string{}},
+	}
+	for _, test := range tests {
+		if got := arrange(test.symbols, test.order); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("arrange(%v, %v): got %v, want %v", test.symbols, test.order, got, test.want)
+		}
+		// The first of the arrangement is the highest bit
+		bits := bitOrder(test.symbols, test.order)
+		for i := range bits {
+			if bits[i] != test.want[len(bits)-1-i] {
+				t.Errorf("bitOrder(%v, %v): got %v, want %v reversed", test.symbols, test.order, bits, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSolveDeterministic(t *testing.T) {
+	symbols := manySymbols(10)
+	formula := "s1 || s4 || s9 && s2"
+	first, err := Solve(formula, symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		result, err := Solve(formula, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Assignment, first.Assignment) {
+			t.Fatalf("run %d: got %v, want %v", i, result.Assignment, first.Assignment)
+		}
+	}
+}
+
+func TestSearchStopsWorkers(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		if _, err := search(context.Background(), "s0 || s1", manySymbols(12), Options{Workers: 4}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Leave the goroutines that just returned some time to be gone
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines before the searches, %d after", before, after)
+	}
+}
+
+// searchPerCombination is the search as it was before the pool of workers:
+// a goroutine for each combination, all of them started at once. It is only
+// kept as the baseline of BenchmarkSearchWorkers
+func searchPerCombination(formula string, symbols []string) (map[string]bool, error) {
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return nil, err
+	}
+	eval, err := compile(expr, symbols)
+	if err != nil {
+		return nil, err
+	}
+	nCombinations, err := countCombinations(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < nCombinations; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if eval(uint64(i)) {
+				results <- i
+			}
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	best := -1
+	for i := range results {
+		if best < 0 || i < best {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil, nil
+	}
+	return combination(best, symbols), nil
+}
+
+// BenchmarkSearchWorkers searches a contradiction over 16 and 20 symbols, so
+// that every combination is evaluated, with a goroutine per combination and
+// with pools of different sizes
+func BenchmarkSearchWorkers(b *testing.B) {
+	for _, n := range []int{16, 20} {
+		symbols := manySymbols(n)
+		b.Run(fmt.Sprintf("symbols=%d/per-combination", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := searchPerCombination("s0 && !s0", symbols); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		for _, workers := range []int{1, 2, 4, 16, 64} {
+			b.Run(fmt.Sprintf("symbols=%d/workers=%d", n, workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := search(context.Background(), "s0 && !s0", symbols, Options{Workers: workers}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestTooManySymbolsError(t *testing.T) {
+	symbols := manySymbols(maxSymbols + 1)
+	_, err := CountSolutions("s0", symbols)
+	want := fmt.Sprintf("too many symbols for brute force (max %d): %d", maxSymbols, maxSymbols+1)
+	if got := errorString(err); got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+	// The limit holds however the combinations are enumerated
+	if _, err := TruthTable("s0", symbols); err == nil {
+		t.Errorf("truth table: expected an error")
+	}
+	if _, err := FindSolutions("s0", symbols, 1); err == nil {
+		t.Errorf("solutions: expected an error")
+	}
+}
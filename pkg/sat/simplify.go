@@ -0,0 +1,300 @@
+package sat
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Cofactor fixes the variable to the value in the formula, returning the
+// simplified formula over the remaining symbols
+func Cofactor(formula, variable string, value bool) (string, error) {
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return "", err
+	}
+	return formatExpr(fold(expr, map[string]bool{variable: value})), nil
+}
+
+// Simplify reduces the formula with the boolean identities: constants are
+// folded, and repeated and complementary operands are merged
+func Simplify(formula string) (string, error) {
+	expr, err := parseChecked(formula)
+	if err != nil {
+		return "", err
+	}
+	return formatExpr(simplify(expr)), nil
+}
+
+// simplify returns the reduced expression as a new expression, adding back
+// only the parentheses it needs. Nodes it doesn't know are kept as they are
+func simplify(node ast.Expr) ast.Expr {
+	switch expr := node.(type) {
+	case *ast.ParenExpr:
+		return simplify(expr.X)
+
+	case *ast.UnaryExpr:
+		if expr.Op != token.NOT {
+			return expr
+		}
+		x := simplify(expr.X)
+		if inner, ok := x.(*ast.UnaryExpr); ok && inner.Op == token.NOT {
+			return inner.X
+		}
+		return negate(x)
+
+	case *ast.BinaryExpr:
+		switch expr.Op {
+		case token.LAND, token.LOR:
+			return simplifyChain(expr)
+
+		case token.XOR, token.NEQ, token.EQL, opIff:
+			x, y := simplify(expr.X), simplify(expr.Y)
+			differ := expr.Op != token.EQL && expr.Op != opIff
+			switch {
+			case formatExpr(x) == formatExpr(y):
+				return constant(!differ)
+			case complements(x, y):
+				return constant(differ)
+			}
+			return fold(&ast.BinaryExpr{X: parenthesize(x, expr.Op), Op: expr.Op, Y: parenthesize(y, expr.Op)}, nil)
+
+		case opImplies:
+			x, y := simplify(expr.X), simplify(expr.Y)
+			switch {
+			case formatExpr(x) == formatExpr(y):
+				return constant(true)
+			case complements(x, y):
+				// Either way the implication holds when the conclusion does
+				return y
+			}
+			return fold(&ast.BinaryExpr{X: parenthesize(x, expr.Op), Op: expr.Op, Y: parenthesize(y, expr.Op)}, nil)
+		}
+		return expr
+
+	case *ast.CallExpr:
+		// The count of a cardinality constraint is kept as it is
+		call, err := resolveCall(expr)
+		if err != nil {
+			return expr
+		}
+		args := append([]ast.Expr(nil), expr.Args[:len(expr.Args)-len(call.operands)]...)
+		for _, arg := range call.operands {
+			args = append(args, simplify(arg))
+		}
+		return fold(&ast.CallExpr{Fun: expr.Fun, Args: args}, nil)
+
+	case *ast.BasicLit:
+		// 1 and 0 stand for the boolean literals
+		if expr.Kind == token.INT && (expr.Value == "1" || expr.Value == "0") {
+			return constant(expr.Value == "1")
+		}
+		return expr
+
+	default:
+		return expr
+	}
+}
+
+// simplifyChain reduces a chain of conjunctions or disjunctions, keeping the
+// first occurrence of every operand in order
+func simplifyChain(expr *ast.BinaryExpr) ast.Expr {
+	// False dominates a conjunction and is its identity in a disjunction, and
+	// the other way around for true
+	dominant := expr.Op == token.LOR
+
+	var operands []ast.Expr
+	seen := make(map[string]bool)
+	for _, operand := range flatten(expr, expr.Op) {
+		operand = simplify(operand)
+		if value, ok := isConstant(operand); ok {
+			if value == dominant {
+				return constant(dominant)
+			}
+			continue
+		}
+
+		// A simplified operand may itself be a chain of the same operator
+		for _, x := range flatten(operand, expr.Op) {
+			key := formatExpr(x)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			operands = append(operands, x)
+		}
+	}
+
+	for i, x := range operands {
+		for _, y := range operands[i+1:] {
+			if complements(x, y) {
+				return constant(dominant)
+			}
+		}
+	}
+
+	if len(operands) == 0 {
+		return constant(!dominant)
+	}
+	result := parenthesize(operands[0], expr.Op)
+	for _, operand := range operands[1:] {
+		result = &ast.BinaryExpr{X: result, Op: expr.Op, Y: parenthesize(operand, expr.Op)}
+	}
+	return result
+}
+
+// complements reports whether one of the simplified expressions is the
+// negation of the other
+func complements(x, y ast.Expr) bool {
+	if not, ok := x.(*ast.UnaryExpr); ok && not.Op == token.NOT && formatExpr(unparen(not.X)) == formatExpr(y) {
+		return true
+	}
+	if not, ok := y.(*ast.UnaryExpr); ok && not.Op == token.NOT && formatExpr(unparen(not.X)) == formatExpr(x) {
+		return true
+	}
+	return false
+}
+
+// unparen returns the expression without its enclosing parentheses
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = paren.X
+	}
+}
+
+// fold replaces the fixed symbols with their value and folds the constants
+// away, returning a new expression. Nodes it doesn't know are kept as they are
+func fold(node ast.Expr, fixed map[string]bool) ast.Expr {
+	switch expr := node.(type) {
+	case *ast.Ident:
+		if value, ok := fixed[expr.Name]; ok {
+			return constant(value)
+		}
+		return expr
+
+	case *ast.ParenExpr:
+		x := fold(expr.X, fixed)
+		if _, ok := x.(*ast.BinaryExpr); ok {
+			return &ast.ParenExpr{X: x}
+		}
+		// Parentheses around anything else are redundant
+		return x
+
+	case *ast.UnaryExpr:
+		if expr.Op != token.NOT {
+			return expr
+		}
+		x := fold(expr.X, fixed)
+		if value, ok := isConstant(x); ok {
+			return constant(!value)
+		}
+		return &ast.UnaryExpr{Op: token.NOT, X: x}
+
+	case *ast.BinaryExpr:
+		x, y := fold(expr.X, fixed), fold(expr.Y, fixed)
+		xValue, xConst := isConstant(x)
+		yValue, yConst := isConstant(y)
+
+		switch expr.Op {
+		case token.LAND, token.LOR:
+			// False dominates a conjunction and is its identity in a
+			// disjunction, and the other way around for true
+			dominant := expr.Op == token.LOR
+			switch {
+			case xConst && xValue == dominant, yConst && yValue == dominant:
+				return constant(dominant)
+			case xConst:
+				return y
+			case yConst:
+				return x
+			}
+
+		case opImplies:
+			// A false premise or a true conclusion make the implication
+			// hold, and a true premise leaves the conclusion
+			switch {
+			case xConst && !xValue, yConst && yValue:
+				return constant(true)
+			case xConst:
+				return y
+			case yConst:
+				return negate(x)
+			}
+
+		case token.XOR, token.NEQ, token.EQL, opIff:
+			// Comparing with a constant either keeps or negates the other
+			// operand
+			differ := expr.Op != token.EQL && expr.Op != opIff
+			switch {
+			case xConst && yConst:
+				return constant((xValue != yValue) == differ)
+			case xConst && xValue != differ, yConst && yValue != differ:
+				if xConst {
+					return y
+				}
+				return x
+			case xConst:
+				return negate(y)
+			case yConst:
+				return negate(x)
+			}
+
+		default:
+			return expr
+		}
+		return &ast.BinaryExpr{X: x, Op: expr.Op, Y: y}
+
+	case *ast.CallExpr:
+		// Calls are only folded when all of their operands are constants
+		call, err := resolveCall(expr)
+		if err != nil {
+			return expr
+		}
+		counted := len(expr.Args) - len(call.operands)
+		args := append([]ast.Expr(nil), expr.Args[:counted]...)
+		values := make([]bool, len(call.operands))
+		constants := true
+		for i, arg := range call.operands {
+			arg = fold(arg, fixed)
+			args = append(args, arg)
+			var ok bool
+			values[i], ok = isConstant(arg)
+			constants = constants && ok
+		}
+		if constants {
+			return constant(call.apply(values))
+		}
+		return &ast.CallExpr{Fun: expr.Fun, Args: args}
+
+	default:
+		return expr
+	}
+}
+
+// constant returns the identifier of the boolean literal
+func constant(value bool) ast.Expr {
+	if value {
+		return ast.NewIdent("true")
+	}
+	return ast.NewIdent("false")
+}
+
+// isConstant reports whether the expression is a boolean literal, and which
+func isConstant(expr ast.Expr) (bool, bool) {
+	if ident, ok := expr.(*ast.Ident); ok && (ident.Name == "true" || ident.Name == "false") {
+		return ident.Name == "true", true
+	}
+	return false, false
+}
+
+// negate returns the negation of the expression, folding the boolean literals
+// and adding the parentheses needed around binary expressions
+func negate(expr ast.Expr) ast.Expr {
+	if value, ok := isConstant(expr); ok {
+		return constant(!value)
+	}
+	return &ast.UnaryExpr{Op: token.NOT, X: group(expr)}
+}
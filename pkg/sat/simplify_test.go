@@ -0,0 +1,116 @@
+package sat
+
+import "testing"
+
+func TestCofactor(t *testing.T) {
+	tests := []struct {
+		formula  string
+		variable string
+		value    bool
+		want     string
+	}{
+		{"a && b", "a", true, "b"},
+		{"a || !b", "b", true, "a"},
+		{"(a || b) && (!a || c)", "a", true, "c"},
+		{"(a || b) && (!a || c)", "a", false, "b"},
+		{"a == b", "b", false, "!a"},
+		{"a && b", "c", true, "a && b"},
+	}
+	for _, test := range tests {
+		got, err := Cofactor(test.formula, test.variable, test.value)
+		if err != nil {
+			t.Fatalf("%s with %s=%t: %v", test.formula, test.variable, test.value, err)
+		}
+		equivalent, counter, err := Equivalent(got, test.want, []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatalf("%s with %s=%t: %v", test.formula, test.variable, test.value, err)
+		}
+		if !equivalent {
+			t.Errorf("%s with %s=%t: got %q, want %q, they differ on %v", test.formula, test.variable, test.value, got, test.want, counter)
+		}
+	}
+}
+
+func TestCofactorContradiction(t *testing.T) {
+	got, err := Cofactor("a && b", "a", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contradiction, model, err := IsContradiction(got, []string{"b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !contradiction {
+		t.Errorf("a && b with a=false: got %q, satisfied by %v, want a contradiction", got, model)
+	}
+}
+
+func TestCofactorErrors(t *testing.T) {
+	if _, err := Cofactor("a &&", "a", true); err == nil {
+		t.Errorf("expected an error for a formula that doesn't parse")
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"a && true", "a"},
+		{"a && false", "false"},
+		{"a || false", "a"},
+		{"a || true", "true"},
+		{"a && !a", "false"},
+		{"a || !a", "true"},
+		{"a || a", "a"},
+		{"!!a && b && a", "a && b"},
+		{"(a && b) || (a && b)", "a && b"},
+		{"a && (b || true)", "a"},
+		{"a && (b || c)", "a && (b || c)"},
+		// The implications, equivalences and calls are kept as written
+		{"a -> b", "a -> b"},
+		{"(a && true) -> b", "a -> b"},
+		{"a -> false", "!a"},
+		{"true -> b", "b"},
+		{"a -> !a", "!a"},
+		{"a <-> (b || false)", "a <-> b"},
+		{"a <-> true", "a"},
+		{"atleast(2, a, b || false, c)", "atleast(2, a, b, c)"},
+		{"atleast(1, 1, 0)", "true"},
+		{"a && 1", "a"},
+	}
+	for _, test := range tests {
+		got, err := Simplify(test.formula)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.formula, got, test.want)
+		}
+		equivalent, counter, err := Equivalent(test.formula, got, []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equivalent {
+			t.Errorf("%s: simplified to %q, which differs on %v", test.formula, got, counter)
+		}
+	}
+}
+
+func TestSimplifyRandom(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	for seed := int64(1); seed <= 200; seed++ {
+		formula := RandomFormula(symbols, 4, seed)
+		got, err := Simplify(formula)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		equivalent, counter, err := Equivalent(formula, got, symbols)
+		if err != nil {
+			t.Fatalf("%s simplified to %s: %v", formula, got, err)
+		}
+		if !equivalent {
+			t.Errorf("%s: simplified to %q, which differs on %v", formula, got, counter)
+		}
+	}
+}
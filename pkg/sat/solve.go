@@ -0,0 +1,240 @@
+// Package sat decides the satisfiability of boolean formulas written with the
+// Go operators, enumerating the combinations of their symbols in parallel
+package sat
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Result is the outcome of solving a formula
+type Result struct {
+	Formula     string          `json:"formula"`
+	Satisfiable bool            `json:"satisfiable"`
+	Assignment  map[string]bool `json:"assignment"` // nil if unsatisfiable
+	Warnings    []string        `json:"warnings,omitempty"`
+}
+
+// Solve looks for the lexicographically smallest combination of the symbols
+// that satisfies the formula, comparing the symbols in alphabetical order and
+// with false before true, so that the answer doesn't depend on the order the
+// workers finish in
+func Solve(formula string, symbols []string) (Result, error) {
+	return SolveContext(context.Background(), formula, symbols)
+}
+
+// SolveContext is like Solve, but gives up with the context error once the
+// context is done
+func SolveContext(ctx context.Context, formula string, symbols []string) (Result, error) {
+	return solveContext(ctx, formula, symbols, Options{})
+}
+
+// solveContext is SolveContext with the options
+func solveContext(ctx context.Context, formula string, symbols []string, opts Options) (Result, error) {
+	symbols, warnings, err := checkRepeated(symbols, opts.Strict)
+	if err != nil {
+		return Result{}, err
+	}
+	model, err := search(ctx, formula, bitOrder(symbols, opts.Order), opts)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Formula: formula, Satisfiable: model != nil, Assignment: model, Warnings: warnings}, nil
+}
+
+// SolveWithAssumptions is like Solve, but only looks among the combinations
+// giving the assumed symbols their value. The assumptions are folded into the
+// formula, so only the other symbols are enumerated
+func SolveWithAssumptions(formula string, symbols []string, assumptions map[string]bool) (Result, error) {
+	symbols, warnings, err := checkRepeated(symbols, false)
+	if err != nil {
+		return Result{}, err
+	}
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := checkSymbols(expr, symbols); err != nil {
+		return Result{}, err
+	}
+
+	fixed := make(map[string]bool, len(assumptions))
+	for symbol, value := range assumptions {
+		if !contains(symbols, symbol) {
+			return Result{}, fmt.Errorf("assumed symbol '%s' not found in input values", symbol)
+		}
+		fixed[symbol] = value
+	}
+
+	model, err := evalPartial(expr, symbols, fixed)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Formula: formula, Satisfiable: model != nil, Assignment: model, Warnings: warnings}, nil
+}
+
+// SolveStats is like SolveContext, also reporting the work of the search
+func SolveStats(ctx context.Context, formula string, symbols []string) (Result, Stats, error) {
+	return solveStats(ctx, formula, symbols, Options{})
+}
+
+// solveStats is SolveStats with the options
+func solveStats(ctx context.Context, formula string, symbols []string, opts Options) (Result, Stats, error) {
+	symbols, warnings, err := checkRepeated(symbols, opts.Strict)
+	if err != nil {
+		return Result{}, Stats{}, err
+	}
+	model, stats, err := searchStats(ctx, formula, bitOrder(symbols, opts.Order), opts)
+	if err != nil {
+		return Result{}, Stats{}, err
+	}
+	return Result{Formula: formula, Satisfiable: model != nil, Assignment: model, Warnings: warnings}, stats, nil
+}
+
+// SolveInOrder is like SolveContext, but tries the combinations in the
+// lexicographic order of the symbols as they are given rather than sorted,
+// the first one being the most significant
+func SolveInOrder(ctx context.Context, formula string, order []string) (Result, error) {
+	return solveContext(ctx, formula, order, Options{Order: order})
+}
+
+// lexOrder returns the symbols sorted so that the combinations come in
+// lexicographic order: the first symbol in alphabetical order is given the
+// highest bit
+func lexOrder(symbols []string) []string {
+	return bitOrder(symbols, nil)
+}
+
+// bitOrder is like lexOrder, but the symbols of the order come first, in
+// order, before the others in alphabetical order. The first symbol of the
+// order is then given the highest bit
+func bitOrder(symbols, order []string) []string {
+	arranged := arrange(symbols, order)
+	for i, j := 0, len(arranged)-1; i < j; i, j = i+1, j-1 {
+		arranged[i], arranged[j] = arranged[j], arranged[i]
+	}
+	return arranged
+}
+
+// arrange returns the symbols of the order that are among the symbols, in
+// order, followed by the others in alphabetical order
+func arrange(symbols, order []string) []string {
+	arranged := make([]string, 0, len(symbols))
+	for _, symbol := range order {
+		if contains(symbols, symbol) && !contains(arranged, symbol) {
+			arranged = append(arranged, symbol)
+		}
+	}
+	rest := make([]string, 0, len(symbols)-len(arranged))
+	for _, symbol := range symbols {
+		if !contains(arranged, symbol) {
+			rest = append(rest, symbol)
+		}
+	}
+	sort.Strings(rest)
+	return append(arranged, rest...)
+}
+
+// SolveAll looks for a combination satisfying all the formulas at once, over
+// the symbols together with the ones used by any of the formulas. The boolean
+// is false if the formulas can't be satisfied together
+func SolveAll(formulas []string, symbols []string) (map[string]bool, bool, error) {
+	seen := make(map[string]bool)
+	var all []string
+	add := func(symbol string) {
+		if !seen[symbol] {
+			seen[symbol] = true
+			all = append(all, symbol)
+		}
+	}
+	for _, symbol := range symbols {
+		add(symbol)
+	}
+
+	conjuncts := make([]string, len(formulas))
+	for i, formula := range formulas {
+		used, err := ExtractSymbols(formula)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, symbol := range used {
+			add(symbol)
+		}
+		conjuncts[i] = "(" + formula + ")"
+	}
+	sort.Strings(all)
+
+	// Without formulas there is nothing to violate
+	conjunction := "true"
+	if len(conjuncts) > 0 {
+		conjunction = strings.Join(conjuncts, " && ")
+	}
+
+	result, err := Solve(conjunction, all)
+	if err != nil {
+		return nil, false, err
+	}
+	return result.Assignment, result.Satisfiable, nil
+}
+
+// UnsatCore returns a subset of the clauses that still can't be satisfied
+// together, over the symbols as in SolveAll. It is minimal by deletion: each
+// clause is dropped in turn if the others are unsatisfiable without it, so
+// that removing any clause left makes the core satisfiable, though a smaller
+// core may exist. It fails if the clauses are satisfiable
+func UnsatCore(clauses []string, symbols []string) ([]string, error) {
+	_, ok, err := SolveAll(clauses, symbols)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return nil, fmt.Errorf("the clauses are satisfiable, so they have no unsatisfiable core")
+	}
+
+	core := append([]string(nil), clauses...)
+	for i := 0; i < len(core); {
+		rest := append(append([]string(nil), core[:i]...), core[i+1:]...)
+		_, ok, err := SolveAll(rest, symbols)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			// The clause is needed, so it stays in the core
+			i++
+		} else {
+			core = rest
+		}
+	}
+	return core, nil
+}
+
+// SolveTrace is like SolveContext, but tries the combinations one at a time
+// in lexicographic order, calling fn with each of them and its result, so that
+// the search can be followed. The values map is reused between calls, so fn
+// must copy it to keep it around
+func SolveTrace(ctx context.Context, formula string, symbols []string, fn func(values map[string]bool, res bool)) (Result, error) {
+	return solveTrace(ctx, formula, symbols, Options{}, fn)
+}
+
+// solveTrace is SolveTrace with the options
+func solveTrace(ctx context.Context, formula string, symbols []string, opts Options, fn func(values map[string]bool, res bool)) (Result, error) {
+	symbols, warnings, err := checkRepeated(symbols, opts.Strict)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var model map[string]bool
+	err = forEachCombination(ctx, formula, bitOrder(symbols, opts.Order), opts, func(values map[string]bool, res bool) bool {
+		fn(values, res)
+		if res {
+			model = copyValues(values)
+		}
+		return !res
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Formula: formula, Satisfiable: model != nil, Assignment: model, Warnings: warnings}, nil
+}
@@ -0,0 +1,337 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSolveResult(t *testing.T) {
+	tests := []struct {
+		formula string
+		symbols []string
+		want    Result
+	}{
+		{"a && !b", []string{"a", "b"}, Result{Formula: "a && !b", Satisfiable: true, Assignment: map[string]bool{"a": true, "b": false}}},
+		{"a || b", []string{"a", "b"}, Result{Formula: "a || b", Satisfiable: true, Assignment: map[string]bool{"a": false, "b": true}}},
+		{"a && !a", []string{"a"}, Result{Formula: "a && !a"}},
+	}
+	for _, test := range tests {
+		got, err := Solve(test.formula, test.symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.formula, got, test.want)
+		}
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	for _, formula := range []string{"a &&", "a && c", "a + b"} {
+		if _, err := Solve(formula, []string{"a", "b"}); err == nil {
+			t.Errorf("%s: expected an error", formula)
+		}
+	}
+}
+
+func TestSolveLiterals(t *testing.T) {
+	tests := []struct {
+		formula     string
+		satisfiable bool
+	}{
+		{"a && false", false},
+		{"a || true", true},
+		{"!false && a", true},
+		{"!true || a && !a", false},
+	}
+	for _, test := range tests {
+		result, err := Solve(test.formula, []string{"a"})
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if result.Satisfiable != test.satisfiable {
+			t.Errorf("%s: got satisfiable %t, want %t", test.formula, result.Satisfiable, test.satisfiable)
+		}
+	}
+
+	// The literals are not symbols
+	symbols, err := ExtractSymbols("a && false || true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(symbols) != 1 || symbols[0] != "a" {
+		t.Errorf("got symbols %v, want [a]", symbols)
+	}
+}
+
+func TestIntegerConstants(t *testing.T) {
+	symbols := []string{"a", "b"}
+	checkTruthTable(t, "a && 1", symbols, func(v []bool) bool { return v[0] })
+	checkTruthTable(t, "a || 0", symbols, func(v []bool) bool { return v[0] })
+	checkTruthTable(t, "!0 && (b || 0) && !(1 && a)", symbols, func(v []bool) bool { return v[1] && !v[0] })
+	checkTruthTable(t, "a == 1", symbols, func(v []bool) bool { return v[0] })
+
+	result, err := Solve("a && 0", []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Satisfiable {
+		t.Errorf("a && 0: expected unsatisfiable")
+	}
+	// The literals are not symbols
+	if symbols, err := ExtractSymbols("a && 1 || 0"); err != nil || !reflect.DeepEqual(symbols, []string{"a"}) {
+		t.Errorf("got symbols %v, %v, want [a]", symbols, err)
+	}
+
+	for _, formula := range []string{"a && 2", "a || 1.0", "a && 0x1", "a && 'x'", "a && \"1\""} {
+		want := "only 0 and 1 can be used as constants"
+		if _, err := Eval(formula, map[string]bool{"a": true}); !strings.Contains(errorString(err), want) {
+			t.Errorf("eval %s: got error %v, want %q", formula, err, want)
+		}
+		if _, err := Compile(formula, []string{"a"}); err == nil {
+			t.Errorf("compile %s: expected an error", formula)
+		}
+		if _, err := Solve(formula, []string{"a"}); err == nil {
+			t.Errorf("solve %s: expected an error", formula)
+		}
+	}
+}
+
+func TestSolveConstants(t *testing.T) {
+	tests := []struct {
+		formula     string
+		satisfiable bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"true || false", true},
+		{"true && !true", false},
+	}
+	for _, test := range tests {
+		symbols, err := ExtractSymbols(test.formula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(symbols) != 0 {
+			t.Fatalf("%s: got symbols %v, want none", test.formula, symbols)
+		}
+		result, err := Solve(test.formula, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if result.Satisfiable != test.satisfiable {
+			t.Errorf("%s: got satisfiable %t, want %t", test.formula, result.Satisfiable, test.satisfiable)
+		}
+		// The single, empty, combination is the model of the true ones
+		if test.satisfiable && (result.Assignment == nil || len(result.Assignment) != 0) {
+			t.Errorf("%s: got assignment %v, want an empty one", test.formula, result.Assignment)
+		}
+	}
+}
+
+func TestSolveContextCancelled(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	// A contradiction over 40 symbols would take far longer than the test
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := SolveContext(ctx, "s0 && !s0", manySymbols(40))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %s to notice the deadline", elapsed)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := SolveContext(ctx, "s0 && !s0", manySymbols(40)); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+
+	// And a deadline passing while the workers are busy
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if _, err := SolveContext(ctx, "s0 && !s0", manySymbols(40)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %s to stop after the deadline", elapsed)
+	}
+
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines before the searches, %d after", before, after)
+	}
+}
+
+func TestSolveAll(t *testing.T) {
+	model, ok, err := SolveAll([]string{"a || b", "!a", "c -> b"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": false, "b": true, "c": false}; !ok || !reflect.DeepEqual(model, want) {
+		t.Errorf("got %v, %t, want %v", model, ok, want)
+	}
+
+	// The given symbols join the ones of the formulas
+	model, ok, err = SolveAll([]string{"a"}, []string{"z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": true, "z": false}; !ok || !reflect.DeepEqual(model, want) {
+		t.Errorf("got %v, %t, want %v", model, ok, want)
+	}
+
+	model, ok, err = SolveAll([]string{"a", "b", "!a"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok || model != nil {
+		t.Errorf("a and !a: got %v, %t, want no model", model, ok)
+	}
+
+	// Without formulas there is nothing to violate
+	if _, ok, err := SolveAll(nil, nil); err != nil || !ok {
+		t.Errorf("no formulas: got %t, %v, want satisfiable", ok, err)
+	}
+
+	if _, _, err := SolveAll([]string{"a", "b &&"}, nil); err == nil {
+		t.Errorf("expected an error for a formula that doesn't parse")
+	}
+}
+
+func TestSolveSmallestModel(t *testing.T) {
+	tests := []struct {
+		formula string
+		symbols []string
+		want    map[string]bool
+	}{
+		{"a || b || c", []string{"a", "b", "c"}, map[string]bool{"a": false, "b": false, "c": true}},
+		// The declared order doesn't matter, only the alphabetical one
+		{"a || b || c", []string{"c", "a", "b"}, map[string]bool{"a": false, "b": false, "c": true}},
+		{"(a || b) && (b -> c)", []string{"b", "c", "a"}, map[string]bool{"a": false, "b": true, "c": true}},
+		{"a != b", []string{"b", "a"}, map[string]bool{"a": false, "b": true}},
+	}
+	for _, test := range tests {
+		result, err := Solve(test.formula, test.symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if !reflect.DeepEqual(result.Assignment, test.want) {
+			t.Errorf("%s over %v: got %v, want %v", test.formula, test.symbols, result.Assignment, test.want)
+		}
+	}
+}
+
+// TestSolveSmallestModelRandom compares the models of Solve with the first
+// solution of an enumeration in lexicographic order, with few and many workers
+func TestSolveSmallestModelRandom(t *testing.T) {
+	symbols := []string{"d", "b", "a", "c", "e"}
+	for seed := int64(1); seed <= 100; seed++ {
+		formula := RandomFormula(symbols, 5, seed)
+		var want map[string]bool
+		for i := 0; i < 1<<len(symbols) && want == nil; i++ {
+			// a is the most significant bit
+			values := map[string]bool{}
+			for j, symbol := range []string{"e", "d", "c", "b", "a"} {
+				values[symbol] = (i>>j)&1 == 1
+			}
+			if res, err := Eval(formula, values); err != nil {
+				t.Fatalf("%s: %v", formula, err)
+			} else if res {
+				want = values
+			}
+		}
+
+		for _, workers := range []int{1, 7} {
+			model, err := search(context.Background(), formula, lexOrder(symbols), Options{Workers: workers})
+			if err != nil {
+				t.Fatalf("%s: %v", formula, err)
+			}
+			if !reflect.DeepEqual(model, want) {
+				t.Errorf("%s with %d workers: got %v, want %v", formula, workers, model, want)
+			}
+		}
+	}
+}
+
+func TestUnsatCore(t *testing.T) {
+	tests := []struct {
+		clauses []string
+		want    []string
+	}{
+		{[]string{"a", "!a", "b"}, []string{"a", "!a"}},
+		{[]string{"c", "a || b", "!a", "d", "!b"}, []string{"a || b", "!a", "!b"}},
+		{[]string{"a && !a"}, []string{"a && !a"}},
+		{[]string{"a", "b", "!a || !b", "false"}, []string{"false"}},
+	}
+	for _, test := range tests {
+		core, err := UnsatCore(test.clauses, nil)
+		if err != nil {
+			t.Fatalf("%q: %v", test.clauses, err)
+		}
+		if !reflect.DeepEqual(core, test.want) {
+			t.Errorf("%q: got core %q, want %q", test.clauses, core, test.want)
+		}
+
+		// The core is unsatisfiable, and dropping any of its clauses
+		// makes it satisfiable
+		if _, ok, err := SolveAll(core, nil); err != nil || ok {
+			t.Errorf("%q: core %q is satisfiable", test.clauses, core)
+		}
+		for i := range core {
+			rest := append(append([]string{}, core[:i]...), core[i+1:]...)
+			if _, ok, err := SolveAll(rest, nil); err != nil || !ok {
+				t.Errorf("%q: core %q is still unsatisfiable without %s", test.clauses, core, core[i])
+			}
+		}
+	}
+
+	if _, err := UnsatCore([]string{"a", "b"}, nil); err == nil {
+		t.Errorf("expected an error for satisfiable clauses")
+	}
+}
+
+func TestSolveStats(t *testing.T) {
+	for n := 1; n <= 12; n++ {
+		symbols := manySymbols(n)
+		for _, formula := range []string{"s0 && !s0", "!s0", RandomFormula(symbols, 4, int64(n))} {
+			result, stats, err := SolveStats(context.Background(), formula, symbols)
+			if err != nil {
+				t.Fatalf("%s: %v", formula, err)
+			}
+			if stats.Variables != n || stats.Combinations != 1<<n {
+				t.Errorf("%s on %d symbols: got %+v, want %d combinations", formula, n, stats, 1<<n)
+			}
+			if stats.Evaluated < 1 || stats.Evaluated > stats.Combinations {
+				t.Errorf("%s on %d symbols: evaluated %d of %d combinations", formula, n, stats.Evaluated, stats.Combinations)
+			}
+			if stats.ShortCircuited != (stats.Evaluated < stats.Combinations) {
+				t.Errorf("%s on %d symbols: got %+v, which contradicts itself", formula, n, stats)
+			}
+			// Only a model can stop the search before the end
+			if !result.Satisfiable && stats.Evaluated != stats.Combinations {
+				t.Errorf("%s on %d symbols: unsatisfiable after %d of %d combinations", formula, n, stats.Evaluated, stats.Combinations)
+			}
+		}
+	}
+
+	// The first combination satisfies it, so the search stops right away
+	_, stats, err := SolveStats(context.Background(), "!s0", manySymbols(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.ShortCircuited || stats.Evaluated > 1000 {
+		t.Errorf("got %+v, want a search stopped after a few combinations", stats)
+	}
+}
@@ -0,0 +1,325 @@
+package sat
+
+import (
+	"context"
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Solver solves formulas like SolveContext, remembering the results so that
+// formulas repeated over the same symbols, even if written differently, are
+// only solved once. The formulas are told apart by their normal form, as
+// with Normalize, folding the case first if the options say so. It is safe
+// for concurrent use
+type Solver struct {
+	opts   Options
+	mu     sync.Mutex
+	cache  map[string]Result
+	logger Logger
+}
+
+// Options change how a solver reads the formulas and searches them. The zero
+// value is what the package level functions use
+type Options struct {
+	// Strict makes the solver fail when some symbol is repeated in a list
+	// of symbols, instead of ignoring the repetitions with a warning
+	Strict bool
+
+	// FoldCase makes the identifiers differing only in case the same
+	// symbol, in the formulas as well as in the lists of symbols. The
+	// symbols are then reported in lower case, except for those such as
+	// True, which would become the constants and are reported in upper
+	// case instead. The names of the functions are left as they are
+	FoldCase bool
+
+	// Postfix makes the solver read the formulas in postfix notation, as
+	// FromRPN does. Their AST is built directly, without the Go parser
+	Postfix bool
+
+	// Progress, if set, is called about every progressInterval while a
+	// formula is searched with how many of its combinations have been
+	// evaluated so far, over the total. It is called from its own goroutine,
+	// never after the search is over, and the searches of the solver running
+	// at the same time all report to it
+	Progress func(explored, total int)
+
+	// Workers is how many goroutines evaluate the combinations of a
+	// search in parallel, runtime.NumCPU() if it is not positive
+	Workers int
+
+	// Order, if set, lists the symbols the combinations are ordered by
+	// first, the first being the most significant, before the others in
+	// alphabetical order. This changes the model found, the order of the
+	// solutions and of the rows of the truth tables, and their columns
+	Order []string
+}
+
+// workers returns how many workers a search runs
+func (o Options) workers() int {
+	if o.Workers <= 0 {
+		return runtime.NumCPU()
+	}
+	return o.Workers
+}
+
+// parseFormula is the package level parseFormula, reading the formula in the
+// notation of the options
+func (o Options) parseFormula(formula string) (ast.Expr, error) {
+	expr, err := o.parseSyntax(formula)
+	if err != nil {
+		return nil, err
+	}
+	return prepare(expr)
+}
+
+// parseSyntax is the package level parseSyntax, reading the formula in the
+// notation of the options
+func (o Options) parseSyntax(formula string) (ast.Expr, error) {
+	if o.Postfix {
+		return parseRPN(formula)
+	}
+	return parseSyntax(formula)
+}
+
+// Logger receives the diagnostic events of a solver as a message followed by
+// alternating keys and values. A *slog.Logger can be used as it is
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// nopLogger discards all the events
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// NewSolver returns a solver with an empty cache and the default options,
+// logging nothing
+func NewSolver() *Solver {
+	return NewSolverWithOptions(Options{})
+}
+
+// NewSolverWithOptions is like NewSolver, with the options
+func NewSolverWithOptions(opts Options) *Solver {
+	s := &Solver{opts: opts, cache: make(map[string]Result), logger: nopLogger{}}
+	if opts.Order != nil {
+		// The order is named like the symbols, and kept from the caller
+		_, order := s.fold("", opts.Order)
+		s.opts.Order = append([]string{}, order...)
+	}
+	return s
+}
+
+// SetLogger makes the solver report its events to the logger, or discard
+// them if it is nil
+func (s *Solver) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	s.mu.Lock()
+	s.logger = logger
+	s.mu.Unlock()
+}
+
+// Solve is like the package level Solve, going through the cache
+func (s *Solver) Solve(formula string, symbols []string) (Result, error) {
+	return s.SolveContext(context.Background(), formula, symbols)
+}
+
+// SolveContext is like the package level SolveContext, going through the
+// cache. Errors are not remembered, so that a formula that timed out can be
+// tried again
+func (s *Solver) SolveContext(ctx context.Context, formula string, symbols []string) (Result, error) {
+	s.mu.Lock()
+	logger := s.logger
+	s.mu.Unlock()
+
+	original := formula
+	formula, symbols = s.fold(formula, symbols)
+
+	// Formulas with the same normal form have the same models. The
+	// cardinality constraints are normalized as they are written, since
+	// their expansion can be far larger than the formula
+	syntax, err := s.opts.parseSyntax(formula)
+	if err != nil {
+		logger.Error("solve failed", "formula", original, "error", err)
+		return Result{}, err
+	}
+	normal := formatExpr(normalize(syntax))
+	key := normal + "\x00" + strings.Join(symbols, "\x00")
+
+	s.mu.Lock()
+	result, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok {
+		logger.Debug("cache hit", "formula", original, "normalized", normal)
+	} else {
+		start := time.Now()
+		result, err = solveContext(ctx, formula, symbols, s.opts)
+		if err != nil {
+			logger.Error("solve failed", "formula", original, "error", err)
+			return Result{}, err
+		}
+		logger.Info("solved", "formula", original, "symbols", len(symbols), "satisfiable", result.Satisfiable, "elapsed", time.Since(start))
+
+		s.mu.Lock()
+		s.cache[key] = result
+		s.mu.Unlock()
+	}
+
+	// The cached result is shared, so callers get their own copy
+	result.Formula = original
+	result.Assignment = copyAssignment(result.Assignment)
+	return result, nil
+}
+
+// SolveStats is like the package level SolveStats, with the options of the
+// solver. The search always runs, so that its work can be reported
+func (s *Solver) SolveStats(ctx context.Context, formula string, symbols []string) (Result, Stats, error) {
+	folded, symbols := s.fold(formula, symbols)
+	result, stats, err := solveStats(ctx, folded, symbols, s.opts)
+	result.Formula = formula
+	return result, stats, err
+}
+
+// SolveTrace is like the package level SolveTrace, with the options of the
+// solver
+func (s *Solver) SolveTrace(ctx context.Context, formula string, symbols []string, fn func(values map[string]bool, res bool)) (Result, error) {
+	folded, symbols := s.fold(formula, symbols)
+	result, err := solveTrace(ctx, folded, symbols, s.opts, fn)
+	result.Formula = formula
+	return result, err
+}
+
+// CountSolutions is like the package level CountSolutions, with the options
+// of the solver. It gives up with the context error once the context is done
+func (s *Solver) CountSolutions(ctx context.Context, formula string, symbols []string) (int, error) {
+	formula, symbols = s.fold(formula, symbols)
+	return countSolutions(ctx, formula, symbols, s.opts)
+}
+
+// FindSolutions is like the package level FindSolutions, with the options of
+// the solver. It gives up with the context error once the context is done
+func (s *Solver) FindSolutions(ctx context.Context, formula string, symbols []string, limit int) ([]map[string]bool, error) {
+	formula, symbols = s.fold(formula, symbols)
+	return findSolutions(ctx, formula, symbols, limit, s.opts)
+}
+
+// TruthTable is like the package level TruthTable, with the options of the
+// solver. With an order, the symbols of the order come first. It also returns
+// the symbols in the order of the columns, as the solver names them. It gives
+// up with the context error once the context is done
+func (s *Solver) TruthTable(ctx context.Context, formula string, symbols []string) ([]string, [][]bool, error) {
+	formula, symbols = s.fold(formula, symbols)
+	symbols, _, err := checkRepeated(symbols, s.opts.Strict)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.opts.Order != nil {
+		symbols = arrange(symbols, s.opts.Order)
+	}
+	table, err := truthTable(ctx, formula, symbols, s.opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return symbols, table, nil
+}
+
+// Order returns the order of the symbols of the solver, as it names them
+func (s *Solver) Order() []string {
+	return s.opts.Order
+}
+
+// ExtractSymbols is like the package level ExtractSymbols, naming the
+// symbols as the solver does
+func (s *Solver) ExtractSymbols(formula string) ([]string, error) {
+	formula, _ = s.fold(formula, nil)
+	expr, err := s.opts.parseFormula(formula)
+	if err != nil {
+		return nil, err
+	}
+	return collectSymbols(expr), nil
+}
+
+// fold returns the formula and the symbols with the symbols in lower case if
+// the solver folds their case
+func (s *Solver) fold(formula string, symbols []string) (string, []string) {
+	if !s.opts.FoldCase {
+		return formula, symbols
+	}
+	folded := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		folded[i] = foldSymbol(symbol)
+	}
+	return foldFormula(formula), folded
+}
+
+// foldFormula folds the case of the identifiers of the formula found by the
+// Go scanner, except for the names of the calls
+func foldFormula(formula string) string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(formula))
+	var s scanner.Scanner
+	s.Init(file, []byte(formula), nil, 0)
+
+	type ident struct {
+		offset int
+		name   string
+	}
+	var idents []ident
+	var last token.Token
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.LPAREN && last == token.IDENT {
+			// The name of a call, left for the builtins to recognize
+			idents = idents[:len(idents)-1]
+		}
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.IDENT {
+			idents = append(idents, ident{file.Offset(pos), lit})
+		}
+		last = tok
+	}
+
+	var b strings.Builder
+	end := 0
+	for _, id := range idents {
+		b.WriteString(formula[end:id.offset])
+		b.WriteString(foldSymbol(id.name))
+		end = id.offset + len(id.name)
+	}
+	b.WriteString(formula[end:])
+	return b.String()
+}
+
+// foldSymbol returns the identifier in lower case, or in upper case if it
+// would otherwise be taken for a boolean constant. The constants stay as
+// they are
+func foldSymbol(symbol string) string {
+	folded := strings.ToLower(symbol)
+	switch {
+	case symbol == "true" || symbol == "false":
+		return symbol
+	case folded == "true" || folded == "false":
+		return strings.ToUpper(symbol)
+	}
+	return folded
+}
+
+// copyAssignment returns a copy of the assignment, nil if it is nil
+func copyAssignment(assignment map[string]bool) map[string]bool {
+	if assignment == nil {
+		return nil
+	}
+	return copyValues(assignment)
+}
@@ -0,0 +1,485 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSolverFoldCase(t *testing.T) {
+	s := NewSolverWithOptions(Options{FoldCase: true})
+	result, err := s.Solve("A && !a", []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Satisfiable {
+		t.Errorf("A && !a: expected unsatisfiable when folding case")
+	}
+
+	symbols, err := s.ExtractSymbols("Foo || foo || BAR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"bar", "foo"}; !reflect.DeepEqual(symbols, want) {
+		t.Errorf("got symbols %v, want %v", symbols, want)
+	}
+
+	// Only the symbols are folded, not the constants they would become
+	// nor the names of the calls
+	symbols, err = s.ExtractSymbols("TRUE && !A || xor(B, c) || false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"TRUE", "a", "b", "c"}; !reflect.DeepEqual(symbols, want) {
+		t.Errorf("got symbols %v, want %v", symbols, want)
+	}
+	result, err = s.Solve("TRUE && !a && true", []string{"True", "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"TRUE": true, "a": false}; !result.Satisfiable || !reflect.DeepEqual(result.Assignment, want) {
+		t.Errorf("TRUE && !a: got %v, want %v", result.Assignment, want)
+	}
+	if _, err := s.Solve("XOR(a, b)", []string{"a", "b"}); errorString(err) != "unknown function 'XOR'" {
+		t.Errorf("XOR(a, b): got error %v", err)
+	}
+
+	// The formula is reported as it was written
+	result, err = s.Solve("X && Y", []string{"X", "y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Formula != "X && Y" {
+		t.Errorf("got formula %q, want %q", result.Formula, "X && Y")
+	}
+	if want := map[string]bool{"x": true, "y": true}; !reflect.DeepEqual(result.Assignment, want) {
+		t.Errorf("got assignment %v, want %v", result.Assignment, want)
+	}
+}
+
+func TestSolverCaseSensitive(t *testing.T) {
+	if _, err := NewSolver().Solve("A && !a", []string{"a"}); err == nil {
+		t.Errorf("A && !a over a: expected an error for the undeclared A")
+	}
+	result, err := NewSolver().Solve("A && !a", []string{"A", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Satisfiable {
+		t.Errorf("A && !a: expected satisfiable with A and a apart")
+	}
+}
+
+func TestSolverStrict(t *testing.T) {
+	symbols := []string{"a", "b", "a"}
+
+	result, err := NewSolver().Solve("a && b", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("got warnings %q, want one about the repeated symbol", result.Warnings)
+	}
+
+	strict := NewSolverWithOptions(Options{Strict: true})
+	if _, err := strict.Solve("a && b", symbols); err == nil {
+		t.Errorf("strict: expected an error for the repeated symbol")
+	}
+	if _, err := strict.CountSolutions(context.Background(), "a && b", symbols); err == nil {
+		t.Errorf("strict count: expected an error for the repeated symbol")
+	}
+	if _, _, err := strict.TruthTable(context.Background(), "a && b", symbols); err == nil {
+		t.Errorf("strict table: expected an error for the repeated symbol")
+	}
+}
+
+func TestSolverProgress(t *testing.T) {
+	var mu sync.Mutex
+	calls, done := 0, false
+	s := NewSolverWithOptions(Options{Progress: func(explored, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if done {
+			t.Errorf("progress reported after the search was over")
+		}
+		if explored < 0 || explored > total || total != 1<<21 {
+			t.Errorf("explored %d of %d combinations", explored, total)
+		}
+	}})
+
+	// A contradiction over enough symbols to outlast a few reports
+	symbols := manySymbols(21)
+	if _, err := s.Solve("s0 && !s0", symbols); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	done = true
+	if calls == 0 {
+		t.Errorf("no progress reported")
+	}
+	mu.Unlock()
+
+	// The other solvers don't report to it
+	if _, err := NewSolver().Solve("s0 && !s0", symbols); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSolverOptionsConcurrent solves with solvers of different options at the
+// same time, which only works if the options are not shared
+func TestSolverOptionsConcurrent(t *testing.T) {
+	folding := NewSolverWithOptions(Options{FoldCase: true})
+	sensitive := NewSolver()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			result, err := folding.Solve("A && !a", []string{"a"})
+			if err != nil || result.Satisfiable {
+				t.Errorf("folding: got %v, %v, want unsatisfiable", result, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			result, err := sensitive.Solve("A && !a", []string{"A", "a"})
+			if err != nil || !result.Satisfiable {
+				t.Errorf("case sensitive: got %v, %v, want satisfiable", result, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// logRecord is an event received by a recordingLogger
+type logRecord struct {
+	level, msg string
+	args       []any
+}
+
+// recordingLogger keeps the events it receives
+type recordingLogger struct {
+	mu      sync.Mutex
+	records []logRecord
+}
+
+func (l *recordingLogger) record(level, msg string, args []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, logRecord{level, msg, args})
+}
+
+// count returns how many events had the message
+func (l *recordingLogger) count(msg string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for _, r := range l.records {
+		if r.msg == msg {
+			n++
+		}
+	}
+	return n
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) { l.record("debug", msg, args) }
+func (l *recordingLogger) Info(msg string, args ...any)  { l.record("info", msg, args) }
+func (l *recordingLogger) Error(msg string, args ...any) { l.record("error", msg, args) }
+
+func TestSolverLogger(t *testing.T) {
+	s := NewSolver()
+	logger := &recordingLogger{}
+	s.SetLogger(logger)
+
+	for _, formula := range []string{"a && !b", "!b && a", "a &&"} {
+		s.Solve(formula, []string{"a", "b"})
+	}
+	if len(logger.records) != 3 {
+		t.Fatalf("got %d records, want 3: %v", len(logger.records), logger.records)
+	}
+
+	solved := logger.records[0]
+	if solved.level != "info" || solved.msg != "solved" {
+		t.Errorf("got %s %q, want info \"solved\"", solved.level, solved.msg)
+	}
+	// The elapsed time varies, the other attributes don't
+	if len(solved.args) != 8 {
+		t.Fatalf("got attributes %v, want 4 pairs", solved.args)
+	}
+	if want := []any{"formula", "a && !b", "symbols", 2, "satisfiable", true, "elapsed"}; !reflect.DeepEqual(solved.args[:7], want) {
+		t.Errorf("got attributes %v, want %v", solved.args[:7], want)
+	}
+	if _, ok := solved.args[7].(time.Duration); !ok {
+		t.Errorf("got elapsed %v, want a duration", solved.args[7])
+	}
+
+	if want := (logRecord{"debug", "cache hit", []any{"formula", "!b && a", "normalized", "!b && a"}}); !reflect.DeepEqual(logger.records[1], want) {
+		t.Errorf("got %v, want %v", logger.records[1], want)
+	}
+
+	failed := logger.records[2]
+	if failed.level != "error" || failed.msg != "solve failed" || len(failed.args) != 4 || failed.args[1] != "a &&" {
+		t.Errorf("got %v, want the error of a &&", failed)
+	}
+	if err, ok := failed.args[3].(error); !ok || !strings.Contains(err.Error(), "error parsing expression") {
+		t.Errorf("got error %v, want the parse error", failed.args[3])
+	}
+
+	// Without a logger the events are discarded
+	s.SetLogger(nil)
+	if _, err := s.Solve("a || b", []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.records) != 3 {
+		t.Errorf("got %d records after removing the logger, want 3", len(logger.records))
+	}
+}
+
+func TestSolverCache(t *testing.T) {
+	tests := []struct {
+		name          string
+		first, second string
+		symbols       []string
+		others        []string // Symbols of the second formula, if not the same
+		opts          Options
+		hit           bool
+	}{
+		{"same", "a && !b", "a && !b", []string{"a", "b"}, nil, Options{}, true},
+		{"commuted", "a && !b", "!b && a", []string{"a", "b"}, nil, Options{}, true},
+		{"parenthesized", "(a || b) || c", "a || (b || c)", []string{"a", "b", "c"}, nil, Options{}, true},
+		{"double negation", "!!a", "a", []string{"a"}, nil, Options{}, true},
+		{"cardinality", "atleast(2, a, b, c) && !!c", "c && (atleast(2, a, b, c))", []string{"a", "b", "c"}, nil, Options{}, true},
+		{"folded", "A && b", "a && B", []string{"a", "b"}, nil, Options{FoldCase: true}, true},
+		{"different", "a && b", "a || b", []string{"a", "b"}, nil, Options{}, false},
+		{"different count", "atleast(1, a, b)", "atleast(2, a, b)", []string{"a", "b"}, nil, Options{}, false},
+		{"different symbols", "a", "a", []string{"a"}, []string{"a", "b"}, Options{}, false},
+		{"case sensitive", "A || a", "a || a", []string{"A", "a"}, nil, Options{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := NewSolverWithOptions(test.opts)
+			logger := &recordingLogger{}
+			s.SetLogger(logger)
+
+			if _, err := s.Solve(test.first, test.symbols); err != nil {
+				t.Fatal(err)
+			}
+			symbols := test.symbols
+			if test.others != nil {
+				symbols = test.others
+			}
+			second, err := s.Solve(test.second, symbols)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hit := logger.count("cache hit") == 1; hit != test.hit {
+				t.Errorf("cache hit: got %t, want %t", hit, test.hit)
+			}
+			if second.Formula != test.second {
+				t.Errorf("got formula %q, want %q", second.Formula, test.second)
+			}
+		})
+	}
+}
+
+func TestSolverCacheCopies(t *testing.T) {
+	s := NewSolver()
+	first, err := s.Solve("a && b", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Assignment["a"] = false
+
+	second, err := s.Solve("a && b", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Assignment["a"] {
+		t.Errorf("changing a result changed the cached one")
+	}
+}
+
+func TestSolverCacheSkipsErrors(t *testing.T) {
+	s := NewSolver()
+	logger := &recordingLogger{}
+	s.SetLogger(logger)
+	for i := 0; i < 2; i++ {
+		if _, err := s.Solve("a && c", []string{"a"}); err == nil {
+			t.Fatalf("expected an error for the undeclared c")
+		}
+	}
+	if n := logger.count("cache hit"); n != 0 {
+		t.Errorf("got %d cache hits for a failing formula", n)
+	}
+}
+
+// BenchmarkSolverCacheHit solves again a formula whose cardinality constraint
+// expands to hundreds of thousands of nodes, which the cache key must not
+// pay for
+func BenchmarkSolverCacheHit(b *testing.B) {
+	symbols := manySymbols(24)
+	formula := "atleast(9, " + strings.Join(symbols, ", ") + ") && !atleast(10, " + strings.Join(symbols, ", ") + ")"
+	s := NewSolver()
+	if _, err := s.Solve(formula, symbols); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Solve(formula, symbols); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSolverOrder(t *testing.T) {
+	s := NewSolverWithOptions(Options{Order: []string{"c", "a"}})
+	symbols := []string{"a", "b", "c"}
+
+	// c is the most significant, then a, then b
+	result, err := s.Solve("a || b || c", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": false, "b": true, "c": false}; !reflect.DeepEqual(result.Assignment, want) {
+		t.Errorf("solve: got %v, want %v", result.Assignment, want)
+	}
+	result, _, err = s.SolveStats(context.Background(), "a || c", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": true, "b": false, "c": false}; !reflect.DeepEqual(result.Assignment, want) {
+		t.Errorf("stats: got %v, want %v", result.Assignment, want)
+	}
+
+	var tried []string
+	_, err = s.SolveTrace(context.Background(), "c && a", symbols, func(values map[string]bool, _ bool) {
+		tried = append(tried, fmt.Sprint(values["c"], values["a"], values["b"]))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"false false false", "false false true", "false true false", "false true true", "true false false", "true false true", "true true false"}
+	if !reflect.DeepEqual(tried, want) {
+		t.Errorf("trace: got %q, want %q", tried, want)
+	}
+
+	solutions, err := s.FindSolutions(context.Background(), "a != c", symbols, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSolutions := []map[string]bool{{"a": true, "b": false, "c": false}, {"a": true, "b": true, "c": false}}
+	if !reflect.DeepEqual(solutions, wantSolutions) {
+		t.Errorf("solutions: got %v, want %v", solutions, wantSolutions)
+	}
+}
+
+func TestSolverOrderTruthTable(t *testing.T) {
+	s := NewSolverWithOptions(Options{Order: []string{"b", "z"}})
+	columns, table, err := s.TruthTable(context.Background(), "a && !b", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b", "a"}; !reflect.DeepEqual(columns, want) {
+		t.Errorf("got columns %v, want %v", columns, want)
+	}
+	// The first column is the most significant
+	want := [][]bool{
+		{false, false, false},
+		{false, true, true},
+		{true, false, false},
+		{true, true, false},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("got table %v, want %v", table, want)
+	}
+}
+
+func TestSolverOrderFoldCase(t *testing.T) {
+	order := []string{"B", "a"}
+	s := NewSolverWithOptions(Options{Order: order, FoldCase: true})
+	order[0] = "x"
+	if want := []string{"b", "a"}; !reflect.DeepEqual(s.Order(), want) {
+		t.Errorf("got order %v, want %v", s.Order(), want)
+	}
+	result, err := s.Solve("A || b", []string{"a", "B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": true, "b": false}; !reflect.DeepEqual(result.Assignment, want) {
+		t.Errorf("got %v, want %v", result.Assignment, want)
+	}
+}
+
+func TestSolverEnumerationsTimeout(t *testing.T) {
+	// A tautology over 40 symbols would take far longer than the test
+	s := NewSolver()
+	symbols := manySymbols(40)
+	formula := "s0 || !s0"
+	enumerations := map[string]func(ctx context.Context) error{
+		"CountSolutions": func(ctx context.Context) error {
+			_, err := s.CountSolutions(ctx, formula, symbols)
+			return err
+		},
+		"FindSolutions": func(ctx context.Context) error {
+			_, err := s.FindSolutions(ctx, formula, symbols, 0)
+			return err
+		},
+		"TruthTable": func(ctx context.Context) error {
+			_, _, err := s.TruthTable(ctx, formula, symbols)
+			return err
+		},
+	}
+	for name, enumerate := range enumerations {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		start := time.Now()
+		if err := enumerate(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: got error %v, want %v", name, err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: took %s to stop after the deadline", name, elapsed)
+		}
+		cancel()
+	}
+}
+
+func TestSolverPostfix(t *testing.T) {
+	s := NewSolverWithOptions(Options{Postfix: true})
+	ctx := context.Background()
+
+	symbols, err := s.ExtractSymbols("a b -> c ! &&")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(symbols, want) {
+		t.Errorf("got symbols %v, want %v", symbols, want)
+	}
+	result, err := s.Solve("a b -> c ! && a &&", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": true, "b": true, "c": false}; !reflect.DeepEqual(result.Assignment, want) || result.Formula != "a b -> c ! && a &&" {
+		t.Errorf("got %+v, want satisfied by %v", result, want)
+	}
+	if count, err := s.CountSolutions(ctx, "a b ||", []string{"a", "b"}); err != nil || count != 3 {
+		t.Errorf("a b ||: got %d solutions, %v, want 3", count, err)
+	}
+	if solutions, err := s.FindSolutions(ctx, "a b ^", []string{"a", "b"}, 0); err != nil || len(solutions) != 2 {
+		t.Errorf("a b ^: got %v, %v, want 2 solutions", solutions, err)
+	}
+	if _, table, err := s.TruthTable(ctx, "a 0 ||", []string{"a"}); err != nil || !reflect.DeepEqual(table, [][]bool{{false, false}, {true, true}}) {
+		t.Errorf("a 0 ||: got %v, %v", table, err)
+	}
+
+	// The infix formulas are not postfix ones
+	if _, err := s.Solve("a && b", []string{"a", "b"}); errorString(err) != "missing operand for '&&'" {
+		t.Errorf("a && b: got error %v", err)
+	}
+}
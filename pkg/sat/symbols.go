@@ -0,0 +1,138 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// ExtractSymbols returns the sorted and de-duplicated propositional symbols
+// used by the formula, leaving out the boolean literals
+func ExtractSymbols(formula string) ([]string, error) {
+	expr, err := parseFormula(formula)
+	if err != nil {
+		return nil, err
+	}
+	return collectSymbols(expr), nil
+}
+
+// checkSymbols reports in a single error every identifier of the parsed
+// formula that is neither one of the symbols nor a boolean literal, so that
+// the searches can fail before starting their workers
+func checkSymbols(expr ast.Expr, symbols []string) error {
+	declared := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		declared[symbol] = true
+	}
+
+	var missing []string
+	for _, symbol := range collectSymbols(expr) {
+		if !declared[symbol] {
+			missing = append(missing, "'"+symbol+"'")
+		}
+	}
+
+	switch len(missing) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("identifier %s not found in input values", missing[0])
+	default:
+		return fmt.Errorf("identifiers %s not found in input values", strings.Join(missing, ", "))
+	}
+}
+
+// uniqueSymbols returns the symbols without their repetitions, in the order
+// of their first occurrence, together with the ones that were repeated
+func uniqueSymbols(symbols []string) ([]string, []string) {
+	seen := make(map[string]bool, len(symbols))
+	var unique, repeated []string
+	for _, symbol := range symbols {
+		if !seen[symbol] {
+			seen[symbol] = true
+			unique = append(unique, symbol)
+		} else if !contains(repeated, symbol) {
+			repeated = append(repeated, symbol)
+		}
+	}
+	if repeated == nil {
+		// Spare a copy in the common case
+		return symbols, nil
+	}
+	return unique, repeated
+}
+
+// dedupeSymbols is checkRepeated for the functions without a result to hold
+// the warning, which ignore the repetitions
+func dedupeSymbols(symbols []string) ([]string, error) {
+	unique, _, err := checkRepeated(symbols, false)
+	return unique, err
+}
+
+// checkRepeated returns the symbols without their repetitions, together with
+// the warning about them for the result, failing instead if strict
+func checkRepeated(symbols []string, strict bool) ([]string, []string, error) {
+	if err := checkConstants(symbols); err != nil {
+		return nil, nil, err
+	}
+	unique, repeated := uniqueSymbols(symbols)
+	if repeated == nil {
+		return unique, nil, nil
+	}
+	if strict {
+		return nil, nil, fmt.Errorf("%s in the input values", describeRepeated(repeated))
+	}
+	return unique, []string{describeRepeated(repeated) + " in the input values, ignoring the repetitions"}, nil
+}
+
+// checkConstants fails if one of the symbols is named like a boolean
+// constant, which always stands for its value in the formulas
+func checkConstants(symbols []string) error {
+	for _, symbol := range symbols {
+		if symbol == "true" || symbol == "false" {
+			return fmt.Errorf("'%s' is a boolean constant and can't be used as a symbol", symbol)
+		}
+	}
+	return nil
+}
+
+// describeRepeated names the repeated symbols
+func describeRepeated(repeated []string) string {
+	quoted := make([]string, len(repeated))
+	for i, symbol := range repeated {
+		quoted[i] = "'" + symbol + "'"
+	}
+	if len(quoted) == 1 {
+		return "symbol " + quoted[0] + " repeated"
+	}
+	return "symbols " + strings.Join(quoted, ", ") + " repeated"
+}
+
+// collectSymbols returns the sorted and de-duplicated identifiers of the
+// expression, leaving out the boolean literals and the function names
+func collectSymbols(expr ast.Expr) []string {
+	seen := make(map[string]bool)
+	symbols := []string{}
+	var inspect func(node ast.Node) bool
+	inspect = func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			// The name of a function is not a symbol, only its arguments
+			for _, arg := range node.Args {
+				ast.Inspect(arg, inspect)
+			}
+			return false
+		case *ast.Ident:
+			if node.Name != "true" && node.Name != "false" && !seen[node.Name] {
+				seen[node.Name] = true
+				symbols = append(symbols, node.Name)
+			}
+		}
+		return true
+	}
+	ast.Inspect(expr, inspect)
+	sort.Strings(symbols)
+
+	return symbols
+}
@@ -0,0 +1,155 @@
+package sat
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCheckSymbols(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"a && b || !c", ""},
+		{"a && true || false", ""},
+		{"a && d", "identifier 'd' not found in input values"},
+		{"d || e && a || d", "identifiers 'd', 'e' not found in input values"},
+		{"majority(a, b, c)", ""},
+		{"atleast(2, a, d, c)", "identifier 'd' not found in input values"},
+	}
+	for _, test := range tests {
+		expr, err := parseFormula(test.formula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = checkSymbols(expr, []string{"a", "b", "c"})
+		if got := errorString(err); got != test.want {
+			t.Errorf("%s: got error %q, want %q", test.formula, got, test.want)
+		}
+	}
+}
+
+func TestSolveChecksSymbolsFirst(t *testing.T) {
+	// The error names the undeclared symbol before any combination is tried
+	_, err := Solve("a && d", []string{"a", "b", "c"})
+	if got, want := errorString(err), "identifier 'd' not found in input values"; got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+}
+
+func TestExtractSymbols(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    []string
+	}{
+		{"b && a || !c && a", []string{"a", "b", "c"}},
+		{"true || false", []string{}},
+		{"xor(a, b) -> atleast(1, c, d)", []string{"a", "b", "c", "d"}},
+		{"1 && x", []string{"x"}},
+	}
+	for _, test := range tests {
+		got, err := ExtractSymbols(test.formula)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.formula, got, test.want)
+		}
+	}
+
+	if _, err := ExtractSymbols("a &&"); err == nil {
+		t.Errorf("expected an error for a formula that doesn't parse")
+	}
+}
+
+// errorString returns the message of the error, empty if it is nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestRepeatedSymbols(t *testing.T) {
+	symbols := []string{"a", "a", "b"}
+	result, err := Solve("a && !b", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": true, "b": false}; !result.Satisfiable || !reflect.DeepEqual(result.Assignment, want) {
+		t.Errorf("got %v, want %v", result.Assignment, want)
+	}
+	if want := []string{"symbol 'a' repeated in the input values, ignoring the repetitions"}; !reflect.DeepEqual(result.Warnings, want) {
+		t.Errorf("got warnings %q, want %q", result.Warnings, want)
+	}
+
+	// The repetitions don't add combinations
+	count, err := CountSolutions("a || b", []string{"a", "b", "a", "b", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("a || b: got %d solutions, want 3", count)
+	}
+
+	_, warnings, err := checkRepeated([]string{"b", "a", "b", "a", "c"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"symbols 'b', 'a' repeated in the input values, ignoring the repetitions"}; !reflect.DeepEqual(warnings, want) {
+		t.Errorf("got warnings %q, want %q", warnings, want)
+	}
+	if _, _, err := checkRepeated(symbols, true); errorString(err) != "symbol 'a' repeated in the input values" {
+		t.Errorf("strict: got error %v", err)
+	}
+}
+
+func TestConstantSymbols(t *testing.T) {
+	// Declaring true or false is rejected, so that every evaluator reads them
+	// as the constants
+	symbols := []string{"a", "true"}
+	want := "'true' is a boolean constant and can't be used as a symbol"
+	checks := map[string]func() error{
+		"Solve": func() error {
+			_, err := Solve("a && !true", symbols)
+			return err
+		},
+		"SolveDPLL": func() error {
+			_, err := SolveDPLL("a && !true", symbols)
+			return err
+		},
+		"CountSolutions": func() error {
+			_, err := CountSolutions("a && !true", symbols)
+			return err
+		},
+		"BuildBDD": func() error {
+			_, err := BuildBDD("a && !true", symbols)
+			return err
+		},
+		"Compile": func() error {
+			_, err := Compile("a && !true", symbols)
+			return err
+		},
+		"Eval": func() error {
+			_, err := Eval("a && !true", map[string]bool{"a": true, "true": false})
+			return err
+		},
+		"FormulaFromTable": func() error {
+			_, err := FormulaFromTable(symbols, make([]bool, 4))
+			return err
+		},
+		"gray": func() error {
+			return forEachGray(context.Background(), "a && !true", symbols, Options{}, func(uint64, bool) bool { return true })
+		},
+	}
+	for name, check := range checks {
+		if got := errorString(check()); got != want {
+			t.Errorf("%s: got error %q, want %q", name, got, want)
+		}
+	}
+
+	if _, err := Solve("a && !true", []string{"a", "false"}); errorString(err) != "'false' is a boolean constant and can't be used as a symbol" {
+		t.Errorf("false: got error %v", err)
+	}
+}
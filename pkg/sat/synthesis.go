@@ -0,0 +1,287 @@
+package sat
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// FormulaFromTable returns the sum of products of the truth table, the
+// disjunction of a conjunction of literals for each true row. The outputs
+// hold the result of each combination of the symbols in ascending bit order,
+// the first symbol being the most significant, as in the rows of TruthTable
+func FormulaFromTable(symbols []string, outputs []bool) (string, error) {
+	if err := checkOutputs(symbols, outputs); err != nil {
+		return "", err
+	}
+
+	var terms [][]literal
+	for i, res := range outputs {
+		if !res {
+			continue
+		}
+		term := make([]literal, len(symbols))
+		for j, symbol := range symbols {
+			term[j] = literal{name: symbol, negated: (i>>(len(symbols)-1-j))&1 == 0}
+		}
+		terms = append(terms, term)
+	}
+	return sumOfProducts(terms), nil
+}
+
+// Minimize returns a minimal sum of products of the truth table, with the
+// fewest terms and then the fewest literals, using the Quine-McCluskey
+// method. The outputs are laid out as in FormulaFromTable. Choosing the cover
+// among the prime implicants is exponential in the worst case, so it is only
+// practical for a handful of symbols
+func Minimize(symbols []string, outputs []bool) (string, error) {
+	if err := checkOutputs(symbols, outputs); err != nil {
+		return "", err
+	}
+
+	var minterms []uint64
+	for i, res := range outputs {
+		if res {
+			minterms = append(minterms, reverseBits(uint64(i), len(symbols)))
+		}
+	}
+
+	primes := primeImplicants(minterms)
+	var terms [][]literal
+	for _, i := range minimumCover(primes, minterms, len(symbols)) {
+		terms = append(terms, primes[i].literals(symbols))
+	}
+	return sumOfProducts(terms), nil
+}
+
+// PrimeImplicants returns the prime implicants of the formula over the
+// symbols, the conjunctions of literals implying it that can't lose any
+// literal without ceasing to. Each one is a list of literals such as "a" or
+// "!b", in the order of the symbols, and an empty one means the formula is a
+// tautology
+func PrimeImplicants(formula string, symbols []string) ([][]string, error) {
+	symbols, err := dedupeSymbols(symbols)
+	if err != nil {
+		return nil, err
+	}
+	results, err := outputs(formula, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	var minterms []uint64
+	for i, res := range results {
+		if res {
+			minterms = append(minterms, reverseBits(uint64(i), len(symbols)))
+		}
+	}
+
+	implicants := [][]string{}
+	for _, x := range primeImplicants(minterms) {
+		term := []string{}
+		for _, l := range x.literals(symbols) {
+			term = append(term, l.String())
+		}
+		implicants = append(implicants, term)
+	}
+	return implicants, nil
+}
+
+// reverseBits returns the combination of n symbols with its bits in the
+// reverse order. The rows of a table have the first symbol as their highest
+// bit, while the implicants have it as their lowest one
+func reverseBits(c uint64, n int) uint64 {
+	return bits.Reverse64(c) >> (64 - n)
+}
+
+// implicant is a product of literals, holding the value of the symbols it
+// fixes and the mask of the ones it leaves free, the first symbol being the
+// lowest bit. The free bits of the value are always zero
+type implicant struct {
+	value, mask uint64
+}
+
+// covers reports whether the combination makes the implicant true
+func (x implicant) covers(c uint64) bool {
+	return c&^x.mask == x.value
+}
+
+// size returns the number of literals of the implicant over n symbols
+func (x implicant) size(n int) int {
+	return n - bits.OnesCount64(x.mask)
+}
+
+// literals returns the literals of the symbols fixed by the implicant
+func (x implicant) literals(symbols []string) []literal {
+	var term []literal
+	for j, symbol := range symbols {
+		if (x.mask>>j)&1 == 0 {
+			term = append(term, literal{name: symbol, negated: (x.value>>j)&1 == 0})
+		}
+	}
+	return term
+}
+
+// primeImplicants merges the minterms into larger implicants until no pair
+// differs in a single fixed symbol, returning the ones that couldn't be
+// merged any further, sorted
+func primeImplicants(minterms []uint64) []implicant {
+	current := make([]implicant, len(minterms))
+	for i, m := range minterms {
+		current[i] = implicant{value: m}
+	}
+
+	var primes []implicant
+	for len(current) > 0 {
+		merged := make(map[implicant]bool)
+		combined := make(map[implicant]bool)
+		for i, x := range current {
+			for _, y := range current[i+1:] {
+				diff := x.value ^ y.value
+				if x.mask != y.mask || bits.OnesCount64(diff) != 1 {
+					continue
+				}
+				merged[implicant{value: x.value &^ diff, mask: x.mask | diff}] = true
+				combined[x], combined[y] = true, true
+			}
+		}
+
+		for _, x := range current {
+			if !combined[x] {
+				primes = append(primes, x)
+			}
+		}
+		current = current[:0]
+		for x := range merged {
+			current = append(current, x)
+		}
+		sortImplicants(current)
+	}
+
+	sortImplicants(primes)
+	return primes
+}
+
+// sortImplicants orders the implicants by their mask and then their value
+func sortImplicants(implicants []implicant) {
+	sort.Slice(implicants, func(i, j int) bool {
+		if implicants[i].mask != implicants[j].mask {
+			return implicants[i].mask < implicants[j].mask
+		}
+		return implicants[i].value < implicants[j].value
+	})
+}
+
+// minimumCover returns the indexes of the primes of a cheapest set covering
+// all the minterms, starting from the essential primes, the only ones to
+// cover some minterm
+func minimumCover(primes []implicant, minterms []uint64, n int) []int {
+	covering := make([][]int, len(minterms))
+	for k, m := range minterms {
+		for i, x := range primes {
+			if x.covers(m) {
+				covering[k] = append(covering[k], i)
+			}
+		}
+	}
+
+	var essential []int
+	chosen := make(map[int]bool)
+	for _, cover := range covering {
+		if len(cover) == 1 && !chosen[cover[0]] {
+			chosen[cover[0]] = true
+			essential = append(essential, cover[0])
+		}
+	}
+
+	cost := func(cover []int) (int, int) {
+		literals := 0
+		for _, i := range cover {
+			literals += primes[i].size(n)
+		}
+		return len(cover), literals
+	}
+
+	// Branch on the primes covering the first minterm left uncovered
+	var best []int
+	var choose func(cover []int)
+	choose = func(cover []int) {
+		uncovered := -1
+		for k, m := range minterms {
+			covered := false
+			for _, i := range cover {
+				if primes[i].covers(m) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				uncovered = k
+				break
+			}
+		}
+
+		if uncovered < 0 {
+			terms, literals := cost(cover)
+			bestTerms, bestLiterals := cost(best)
+			if best == nil || terms < bestTerms || terms == bestTerms && literals < bestLiterals {
+				best = append([]int(nil), cover...)
+			}
+			return
+		}
+		// Another term is needed, which can't beat a cover already as long
+		if best != nil && len(cover) >= len(best) {
+			return
+		}
+		for _, i := range covering[uncovered] {
+			choose(append(cover, i))
+		}
+	}
+	choose(essential)
+
+	sort.Ints(best)
+	return best
+}
+
+// checkOutputs makes sure there is an output for each combination of the
+// symbols, none of them named like a constant
+func checkOutputs(symbols []string, outputs []bool) error {
+	if err := checkConstants(symbols); err != nil {
+		return err
+	}
+	nCombinations, err := countCombinations(symbols)
+	if err != nil {
+		return err
+	}
+	if len(outputs) != nCombinations {
+		return fmt.Errorf("expected %d outputs for %d symbols, got %d", nCombinations, len(symbols), len(outputs))
+	}
+	return nil
+}
+
+// sumOfProducts renders the disjunction of the conjunctions of literals,
+// which is false without terms, while an empty term is true
+func sumOfProducts(terms [][]literal) string {
+	if len(terms) == 0 {
+		return "false"
+	}
+
+	products := make([]string, len(terms))
+	for i, term := range terms {
+		literals := make([]string, len(term))
+		for j, l := range term {
+			literals[j] = l.String()
+		}
+
+		switch {
+		case len(term) == 0:
+			return "true"
+		case len(term) > 1 && len(terms) > 1:
+			products[i] = "(" + strings.Join(literals, " && ") + ")"
+		default:
+			products[i] = strings.Join(literals, " && ")
+		}
+	}
+	return strings.Join(products, " || ")
+}
@@ -0,0 +1,198 @@
+package sat
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormulaFromTable(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	for seed := int64(1); seed <= 100; seed++ {
+		formula := RandomFormula(symbols, 4, seed)
+		results, err := outputs(formula, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		got, err := FormulaFromTable(symbols, results)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		equivalent, counter, err := Equivalent(formula, got, symbols)
+		if err != nil {
+			t.Fatalf("%s from the table of %s: %v", got, formula, err)
+		}
+		if !equivalent {
+			t.Errorf("%s: got %q from its table, which differs on %v", formula, got, counter)
+		}
+	}
+}
+
+func TestFormulaFromTableRows(t *testing.T) {
+	tests := []struct {
+		outputs []bool
+		want    string
+	}{
+		{[]bool{false, false, false, false}, "false"},
+		{[]bool{false, false, false, true}, "a && b"},
+		// The rows come in lexicographic order, a being the highest bit
+		{[]bool{false, true, false, false}, "!a && b"},
+		{[]bool{false, true, true, false}, "(!a && b) || (a && !b)"},
+	}
+	for _, test := range tests {
+		got, err := FormulaFromTable([]string{"a", "b"}, test.outputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("%v: got %q, want %q", test.outputs, got, test.want)
+		}
+	}
+
+	if _, err := FormulaFromTable([]string{"a", "b"}, []bool{true, false}); err == nil {
+		t.Errorf("expected an error for a table too short")
+	}
+}
+
+// TestFormulaFromTableRoundTrip checks that the formula built from a table
+// has that same table
+func TestFormulaFromTableRoundTrip(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	table := []bool{true, false, false, true, true, true, false, true}
+	formula, err := FormulaFromTable(symbols, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := outputs(formula, symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, table) {
+		t.Errorf("%s: got table %v, want %v", formula, got, table)
+	}
+}
+
+// minterms returns the table over n symbols true on the given combinations,
+// numbered with the first symbol as the lowest bit, as in the cases below
+func minterms(n int, ones ...int) []bool {
+	table := make([]bool, 1<<n)
+	for _, i := range ones {
+		table[reverseBits(uint64(i), n)] = true
+	}
+	return table
+}
+
+func TestMinimize(t *testing.T) {
+	abc := []string{"a", "b", "c"}
+	abcd := []string{"a", "b", "c", "d"}
+	tests := []struct {
+		name    string
+		symbols []string
+		outputs []bool
+		want    string
+	}{
+		{"multiplexer", abc, minterms(3, 3, 4, 6, 7), "(!a && c) || (a && b)"},
+		{"majority", abc, minterms(3, 3, 5, 6, 7), "(b && c) || (a && c) || (a && b)"},
+		{"tautology", abc, minterms(3, 0, 1, 2, 3, 4, 5, 6, 7), "true"},
+		{"contradiction", abc, minterms(3), "false"},
+		{"single literal", abc, minterms(3, 1, 3, 5, 7), "a"},
+		// The classic Σm(0, 1, 2, 5, 6, 7, 8, 9, 10, 14) and Σm(0, 2, 5, 7, 8,
+		// 10, 13, 15), with a as the lowest bit
+		{"classic", abcd, minterms(4, 0, 1, 2, 5, 6, 7, 8, 9, 10, 14), "(a && c && !d) || (!b && !c) || (!a && b)"},
+		{"corners", abcd, minterms(4, 0, 2, 5, 7, 8, 10, 13, 15), "(!a && !c) || (a && c)"},
+	}
+	for _, test := range tests {
+		got, err := Minimize(test.symbols, test.outputs)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+		results, err := outputs(got, test.symbols)
+		if err != nil {
+			t.Fatalf("%s: %s: %v", test.name, got, err)
+		}
+		if !reflect.DeepEqual(results, test.outputs) {
+			t.Errorf("%s: %s has table %v, want %v", test.name, got, results, test.outputs)
+		}
+	}
+}
+
+func TestMinimizeRandom(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	for seed := int64(1); seed <= 100; seed++ {
+		formula := RandomFormula(symbols, 5, seed)
+		table, err := outputs(formula, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		got, err := Minimize(symbols, table)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		minimized, err := outputs(got, symbols)
+		if err != nil {
+			t.Fatalf("%s minimized to %s: %v", formula, got, err)
+		}
+		if !reflect.DeepEqual(minimized, table) {
+			t.Errorf("%s: minimized to %q, which has a different table", formula, got)
+		}
+	}
+}
+
+func TestPrimeImplicants(t *testing.T) {
+	symbols := []string{"a", "b", "c"}
+	tests := []struct {
+		formula string
+		want    [][]string
+	}{
+		// The consensus b && c is prime too, although no minimal cover needs it
+		{"a && b || !a && c", [][]string{{"b", "c"}, {"!a", "c"}, {"a", "b"}}},
+		{"majority(a, b, c)", [][]string{{"b", "c"}, {"a", "c"}, {"a", "b"}}},
+		{"a != b", [][]string{{"a", "!b"}, {"!a", "b"}}},
+		{"a && b && c", [][]string{{"a", "b", "c"}}},
+		{"a", [][]string{{"a"}}},
+		{"a || !a", [][]string{{}}},
+		{"a && !a", [][]string{}},
+	}
+	for _, test := range tests {
+		got, err := PrimeImplicants(test.formula, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.formula, got, test.want)
+		}
+	}
+}
+
+// TestPrimeImplicantsPrime checks on random formulas that each implicant
+// implies the formula, and stops doing so without any of its literals
+func TestPrimeImplicantsPrime(t *testing.T) {
+	symbols := []string{"a", "b", "c", "d"}
+	conjunction := func(literals []string) string {
+		if len(literals) == 0 {
+			return "true"
+		}
+		return strings.Join(literals, " && ")
+	}
+	for seed := int64(1); seed <= 50; seed++ {
+		formula := RandomFormula(symbols, 4, seed)
+		implicants, err := PrimeImplicants(formula, symbols)
+		if err != nil {
+			t.Fatalf("%s: %v", formula, err)
+		}
+		for _, implicant := range implicants {
+			if entails, _, err := Entails(conjunction(implicant), formula, symbols); err != nil || !entails {
+				t.Errorf("%s: %q doesn't imply it", formula, implicant)
+			}
+			for i := range implicant {
+				shorter := append(append([]string{}, implicant[:i]...), implicant[i+1:]...)
+				if entails, _, _ := Entails(conjunction(shorter), formula, symbols); entails {
+					t.Errorf("%s: %q still implies it without %s", formula, implicant, implicant[i])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// PrintTree renders the parsed formula as an indented tree, with each
+// operator on its own line above its operands
+func PrintTree(formula string) (string, error) {
+	expr, err := parseChecked(formula)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := writeTree(&b, expr, "", ""); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// writeTree writes the node of the expression after the branch, and its
+// operands below it, each line starting with the indent. Parentheses only
+// group and get no line of their own
+func writeTree(b *strings.Builder, node ast.Expr, branch, indent string) error {
+	var label string
+	var children []ast.Expr
+	switch expr := node.(type) {
+	case *ast.Ident:
+		label = expr.Name
+	case *ast.BasicLit:
+		// The count of a cardinality constraint
+		label = expr.Value
+	case *ast.UnaryExpr:
+		label, children = expr.Op.String(), []ast.Expr{expr.X}
+	case *ast.BinaryExpr:
+		label, children = operatorString(expr.Op), []ast.Expr{expr.X, expr.Y}
+	case *ast.CallExpr:
+		ident, ok := expr.Fun.(*ast.Ident)
+		if !ok {
+			return fmt.Errorf("unsupported function: %T", expr.Fun)
+		}
+		label, children = ident.Name, expr.Args
+	case *ast.ParenExpr:
+		return writeTree(b, expr.X, branch, indent)
+	default:
+		return fmt.Errorf("unsupported expression type: %T", node)
+	}
+
+	fmt.Fprintf(b, "%s%s%s\n", indent, branch, label)
+
+	// The operands hang below the node, at the position of its branch
+	switch branch {
+	case "├─ ":
+		indent += "│  "
+	case "└─ ":
+		indent += "   "
+	}
+	for i, child := range children {
+		branch := "├─ "
+		if i == len(children)-1 {
+			branch = "└─ "
+		}
+		if err := writeTree(b, child, branch, indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package sat
+
+import "testing"
+
+func TestPrintTree(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"a", "a\n"},
+		{"a && !b", "" +
+			"&&\n" +
+			"├─ a\n" +
+			"└─ !\n" +
+			"   └─ b\n"},
+		{"(a || b) && !(c && a)", "" +
+			"&&\n" +
+			"├─ ||\n" +
+			"│  ├─ a\n" +
+			"│  └─ b\n" +
+			"└─ !\n" +
+			"   └─ &&\n" +
+			"      ├─ c\n" +
+			"      └─ a\n"},
+		// The implications and the calls are shown as written
+		{"a -> b <-> atleast(2, a, b, c)", "" +
+			"<->\n" +
+			"├─ ->\n" +
+			"│  ├─ a\n" +
+			"│  └─ b\n" +
+			"└─ atleast\n" +
+			"   ├─ 2\n" +
+			"   ├─ a\n" +
+			"   ├─ b\n" +
+			"   └─ c\n"},
+	}
+	for _, test := range tests {
+		got, err := PrintTree(test.formula)
+		if err != nil {
+			t.Fatalf("%s: %v", test.formula, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.formula, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package sat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// tseitin encodes a formula into numbered clauses with the Tseitin
+// transformation: each operator gets a variable of its own, constrained to
+// be equivalent to its result on its operands. The clauses grow linearly with
+// the formula, unlike the CNF by distribution, at the cost of the extra
+// variables. The symbols are numbered from 1 in order, before them
+type tseitin struct {
+	variables map[string]int
+	clauses   [][]int
+	next      int              // Next variable to hand out
+	memo      map[ast.Expr]int // Literal of the nodes already encoded
+}
+
+// newTseitin returns an encoder over the symbols, with no clause yet
+func newTseitin(symbols []string) *tseitin {
+	t := &tseitin{variables: make(map[string]int, len(symbols)), memo: make(map[ast.Expr]int)}
+	for i, symbol := range symbols {
+		t.variables[symbol] = i + 1
+	}
+	t.next = len(symbols) + 1
+	return t
+}
+
+// fresh returns a new variable
+func (t *tseitin) fresh() int {
+	v := t.next
+	t.next++
+	return v
+}
+
+// add appends the clause
+func (t *tseitin) add(literals ...int) {
+	t.clauses = append(t.clauses, literals)
+}
+
+// assert encodes the expression and requires it to be true
+func (t *tseitin) assert(expr ast.Expr) error {
+	l, err := t.encode(expr)
+	if err != nil {
+		return err
+	}
+	t.add(l)
+	return nil
+}
+
+// encode returns the literal equivalent to the expression, adding the
+// clauses defining it. The nodes are memoized, so that the shared nodes of an
+// expanded cardinality constraint are only encoded once
+func (t *tseitin) encode(node ast.Expr) (int, error) {
+	if l, ok := t.memo[node]; ok {
+		return l, nil
+	}
+	l, err := t.encodeNode(node)
+	if err != nil {
+		return 0, err
+	}
+	t.memo[node] = l
+	return l, nil
+}
+
+// encodeNode is encode without the memo
+func (t *tseitin) encodeNode(node ast.Expr) (int, error) {
+	switch expr := node.(type) {
+	case *ast.Ident:
+		if v, ok := t.variables[expr.Name]; ok {
+			return v, nil
+		}
+		switch expr.Name {
+		case "true", "false":
+			// A variable forced to the value of the constant
+			v := t.fresh()
+			if expr.Name == "true" {
+				t.add(v)
+			} else {
+				t.add(-v)
+			}
+			return v, nil
+		}
+		return 0, fmt.Errorf("identifier '%s' not found in input values", expr.Name)
+
+	case *ast.UnaryExpr:
+		if expr.Op != token.NOT {
+			return 0, fmt.Errorf("unsupported unary operator: %s", expr.Op)
+		}
+		x, err := t.encode(expr.X)
+		return -x, err
+
+	case *ast.BinaryExpr:
+		x, err := t.encode(expr.X)
+		if err != nil {
+			return 0, err
+		}
+		y, err := t.encode(expr.Y)
+		if err != nil {
+			return 0, err
+		}
+
+		v := t.fresh()
+		switch expr.Op {
+		case token.LAND:
+			t.add(-v, x)
+			t.add(-v, y)
+			t.add(v, -x, -y)
+		case token.LOR:
+			t.add(v, -x)
+			t.add(v, -y)
+			t.add(-v, x, y)
+		case token.XOR, token.NEQ, token.EQL:
+			t.add(-v, x, y)
+			t.add(-v, -x, -y)
+			t.add(v, -x, y)
+			t.add(v, x, -y)
+			// Equal operands are the negation of differing ones
+			if expr.Op == token.EQL {
+				return -v, nil
+			}
+		default:
+			return 0, fmt.Errorf("unsupported binary operator: %s", expr.Op)
+		}
+		return v, nil
+
+	case *ast.CallExpr:
+		lowered, err := lowerCall(expr, false)
+		if err != nil {
+			return 0, err
+		}
+		return t.encode(lowered)
+
+	case *ast.ParenExpr:
+		return t.encode(expr.X)
+
+	default:
+		return 0, fmt.Errorf("unsupported expression type: %T", node)
+	}
+}
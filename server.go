@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"psc-project/pkg/sat"
+)
+
+// solveRequest is the body of a request to solve a formula
+type solveRequest struct {
+	Formula string `json:"formula"`
+}
+
+// maxRequestBytes is the largest request body the service reads
+const maxRequestBytes = 1 << 20
+
+// newHandler returns the handler of the HTTP service, which solves the
+// formula posted to /solve with the solver against the symbols it uses,
+// giving up on it after the timeout unless it is zero
+func newHandler(solver *sat.Solver, timeout time.Duration) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solve", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, jsonResult{Error: "only POST is allowed"})
+			return
+		}
+
+		var body solveRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRequestBytes)).Decode(&body); err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeJSON(w, status, jsonResult{Error: "invalid request body: " + err.Error()})
+			return
+		}
+
+		symbols, err := solver.ExtractSymbols(body.Formula)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, jsonResult{Result: sat.Result{Formula: body.Formula}, Error: err.Error()})
+			return
+		}
+
+		ctx := req.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		result, err := solver.SolveContext(ctx, body.Formula, symbols)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			writeJSON(w, http.StatusGatewayTimeout, jsonResult{Result: sat.Result{Formula: body.Formula}, Error: "timeout"})
+		case errors.Is(err, context.Canceled):
+			// The client went away, and is unlikely to read the response
+			writeJSON(w, http.StatusServiceUnavailable, jsonResult{Result: sat.Result{Formula: body.Formula}, Error: "canceled"})
+		case err != nil:
+			writeJSON(w, http.StatusBadRequest, jsonResult{Result: sat.Result{Formula: body.Formula}, Error: err.Error()})
+		default:
+			writeJSON(w, http.StatusOK, jsonResult{Result: result})
+		}
+	})
+	return mux
+}
+
+// writeJSON sends the result as the JSON body of the response with the status
+func writeJSON(w http.ResponseWriter, status int, result jsonResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	// There is nothing left to do if the client went away
+	_ = enc.Encode(result)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"psc-project/pkg/sat"
+)
+
+// postSolve posts the body to /solve of the handler, returning the status
+// and the decoded result
+func postSolve(t *testing.T, h http.Handler, body string) (int, jsonResult) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/solve", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got content type %q, want application/json", ct)
+	}
+	var result jsonResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("%s: %v", rec.Body.String(), err)
+	}
+	return rec.Code, result
+}
+
+func TestHandlerSolve(t *testing.T) {
+	h := newHandler(sat.NewSolver(), 0)
+
+	status, result := postSolve(t, h, `{"formula": "a && !b"}`)
+	if status != http.StatusOK {
+		t.Errorf("got status %d, want %d", status, http.StatusOK)
+	}
+	if want := map[string]bool{"a": true, "b": false}; !result.Satisfiable || !reflect.DeepEqual(result.Assignment, want) || result.Error != "" {
+		t.Errorf("got %+v, want satisfied by %v", result, want)
+	}
+
+	status, result = postSolve(t, h, `{"formula": "a && !a"}`)
+	if status != http.StatusOK {
+		t.Errorf("got status %d, want %d", status, http.StatusOK)
+	}
+	if result.Satisfiable || result.Assignment != nil || result.Formula != "a && !a" {
+		t.Errorf("got %+v, want unsatisfiable", result)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.Handler
+		body    string
+		status  int
+		want    string
+	}{
+		{"parse error", newHandler(sat.NewSolver(), 0), `{"formula": "a &&"}`, http.StatusBadRequest, "error parsing expression"},
+		{"invalid body", newHandler(sat.NewSolver(), 0), `{"formula": `, http.StatusBadRequest, "invalid request body"},
+		{"timeout", newHandler(sat.NewSolver(), time.Nanosecond), `{"formula": "` + contradiction(40) + `"}`, http.StatusGatewayTimeout, "timeout"},
+		{"too large", newHandler(sat.NewSolver(), 0), `{"formula": "` + strings.Repeat("a || ", maxRequestBytes/5) + `a"}`, http.StatusRequestEntityTooLarge, "request body too large"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, result := postSolve(t, test.handler, test.body)
+			if status != test.status {
+				t.Errorf("got status %d, want %d", status, test.status)
+			}
+			if !strings.Contains(result.Error, test.want) {
+				t.Errorf("got error %q, want %q", result.Error, test.want)
+			}
+		})
+	}
+}
+
+func TestHandlerSolver(t *testing.T) {
+	// The options of the solver apply to the requests
+	h := newHandler(sat.NewSolverWithOptions(sat.Options{FoldCase: true}), 0)
+	status, result := postSolve(t, h, `{"formula": "A && !a"}`)
+	if status != http.StatusOK || result.Satisfiable {
+		t.Errorf("A && !a: got status %d and %+v, want it unsatisfiable when folding case", status, result)
+	}
+}
+
+func TestHandlerCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/solve", strings.NewReader(`{"formula": "`+contradiction(40)+`"}`)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	newHandler(sat.NewSolver(), 0).ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHandlerMethod(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newHandler(sat.NewSolver(), 0).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/solve", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if allow := rec.Header().Get("Allow"); allow != http.MethodPost {
+		t.Errorf("got Allow %q, want %q", allow, http.MethodPost)
+	}
+}
+
+// contradiction returns a formula over n symbols that no combination
+// satisfies, so that its search goes through all of them
+func contradiction(n int) string {
+	symbols := make([]string, n)
+	for i := range symbols {
+		symbols[i] = "s" + strconv.Itoa(i)
+	}
+	return "s0 && !s0 && (" + strings.Join(symbols, " || ") + ")"
+}